@@ -0,0 +1,147 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+)
+
+const (
+	Addr_Type_BLANK = iota
+	Addr_Type_IPV4
+	Addr_Type_IPV6
+	Addr_Type_MAC
+)
+
+var (
+	ipType           = reflect.TypeOf(net.IP{})
+	hardwareAddrType = reflect.TypeOf(net.HardwareAddr{})
+)
+
+// hasAddr reports whether the tag has an address type.
+func (c *tagConfig) hasAddr() bool {
+	return c != nil && c.addr != Addr_Type_BLANK
+}
+
+// sizeOfAddr returns the size of an address field which has the struct tag cnf.
+func sizeOfAddr(cnf *tagConfig) int {
+	if cnf == nil {
+		return 0
+	}
+	switch cnf.addr {
+	case Addr_Type_IPV4:
+		return net.IPv4len
+	case Addr_Type_IPV6:
+		return net.IPv6len
+	case Addr_Type_MAC:
+		if cnf.size > 0 {
+			return cnf.size
+		}
+		return 6
+	}
+	return 0
+}
+
+// readAddr reads an address in network order from b and fill v.
+// v is net.IP, net.HardwareAddr, netip.Addr or byte array.
+func readAddr(b []byte, v reflect.Value, cnf *tagConfig, index *int) error {
+	size := sizeOfAddr(cnf)
+	bs := make([]byte, size)
+	copy(bs, b[*index:*index+size])
+
+	var val reflect.Value
+	switch {
+	case v.Type() == ipType && cnf.addr != Addr_Type_MAC:
+		val = reflect.ValueOf(net.IP(bs))
+	case v.Type() == hardwareAddrType && cnf.addr == Addr_Type_MAC:
+		val = reflect.ValueOf(net.HardwareAddr(bs))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == size:
+		val = reflect.New(v.Type()).Elem()
+		reflect.Copy(val, reflect.ValueOf(bs))
+	default:
+		var err error
+		val, err = readNetipAddr(bs, v, cnf)
+		if err != nil {
+			return err
+		}
+	}
+	*index += size
+
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.Set(val)
+	return nil
+}
+
+// writeAddr writes an address v to b in network order.
+func writeAddr(v reflect.Value, cnf *tagConfig, b []byte, index *int) error {
+	size := sizeOfAddr(cnf)
+	var bs []byte
+
+	switch {
+	case v.Type() == ipType && cnf.addr != Addr_Type_MAC:
+		ip := v.Interface().(net.IP)
+		if ip != nil {
+			if cnf.addr == Addr_Type_IPV4 {
+				bs = ip.To4()
+			} else {
+				bs = ip.To16()
+			}
+			if bs == nil {
+				return fmt.Errorf("%s is not %s", ip, addrName(cnf.addr))
+			}
+		}
+	case v.Type() == hardwareAddrType && cnf.addr == Addr_Type_MAC:
+		bs = v.Interface().(net.HardwareAddr)
+		if bs != nil && len(bs) != size {
+			return fmt.Errorf("invalid length of mac %s, expect=%d", net.HardwareAddr(bs), size)
+		}
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 && v.Len() == size:
+		bs = make([]byte, size)
+		reflect.Copy(reflect.ValueOf(bs), v)
+	default:
+		var err error
+		bs, err = writeNetipAddr(v, cnf)
+		if err != nil {
+			return err
+		}
+	}
+
+	/* nil address is written as zero */
+	fill(b, index, size, 0)
+	copy(b[*index-size:*index], bs)
+	return nil
+}
+
+func addrName(t int) string {
+	switch t {
+	case Addr_Type_IPV4:
+		return "ipv4"
+	case Addr_Type_IPV6:
+		return "ipv6"
+	case Addr_Type_MAC:
+		return "mac"
+	}
+	return ""
+}
+
+func errAddrType(v reflect.Value, cnf *tagConfig) error {
+	return fmt.Errorf("Not Supported %s as %s", v.Type(), addrName(cnf.addr))
+}
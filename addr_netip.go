@@ -0,0 +1,56 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+)
+
+var netipAddrType = reflect.TypeOf(netip.Addr{})
+
+// readNetipAddr returns netip.Addr of bs if v is netip.Addr.
+func readNetipAddr(bs []byte, v reflect.Value, cnf *tagConfig) (reflect.Value, error) {
+	if v.Type() != netipAddrType || cnf.addr == Addr_Type_MAC {
+		return reflect.Value{}, errAddrType(v, cnf)
+	}
+	addr, _ := netip.AddrFromSlice(bs)
+	return reflect.ValueOf(addr), nil
+}
+
+// writeNetipAddr returns bytes of netip.Addr v.
+func writeNetipAddr(v reflect.Value, cnf *tagConfig) ([]byte, error) {
+	if v.Type() != netipAddrType || cnf.addr == Addr_Type_MAC {
+		return nil, errAddrType(v, cnf)
+	}
+	addr := v.Interface().(netip.Addr)
+	switch {
+	case !addr.IsValid():
+		return nil, nil
+	case cnf.addr == Addr_Type_IPV4:
+		addr = addr.Unmap()
+		if !addr.Is4() {
+			return nil, fmt.Errorf("%s is not ipv4", addr)
+		}
+		b := addr.As4()
+		return b[:], nil
+	default:
+		b := addr.As16()
+		return b[:], nil
+	}
+}
@@ -0,0 +1,59 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"net/netip"
+	"testing"
+)
+
+func TestNetipAddr(t *testing.T) {
+	type S struct {
+		Src netip.Addr `endian:"ipv4"`
+		Dst netip.Addr `endian:"ipv6"`
+	}
+	raw := []byte{
+		192, 168, 0, 1,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+	}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Src != netip.MustParseAddr("192.168.0.1") {
+		t.Errorf("Src mismatch given=%s", s.Src)
+	}
+	if s.Dst != netip.MustParseAddr("2001:db8::1") {
+		t.Errorf("Dst mismatch given=%s", s.Dst)
+	}
+
+	ret, err := endian.Marshal(endian.LittleEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	s.Src = netip.MustParseAddr("::1")
+	if _, err := endian.Marshal(endian.LittleEndian, s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"net"
+	"testing"
+)
+
+func TestAddr(t *testing.T) {
+	type S struct {
+		Src  net.IP           `endian:"ipv4"`
+		Dst  net.IP           `endian:"ipv6"`
+		MAC  net.HardwareAddr `endian:"mac"`
+		Raw  [4]byte          `endian:"ipv4"`
+		Port uint16
+	}
+	raw := []byte{
+		192, 168, 0, 1,
+		0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+		10, 0, 0, 1,
+		0x00, 0x50,
+	}
+
+	for _, order := range []endian.ByteOrder{endian.BigEndian, endian.LittleEndian} {
+		var s S
+		if err := endian.Unmarshal(raw, order, &s); err != nil {
+			t.Fatalf("endian.Unmarshal err=%s", err)
+		}
+		if !s.Src.Equal(net.ParseIP("192.168.0.1")) {
+			t.Errorf("Src mismatch given=%s", s.Src)
+		}
+		if !s.Dst.Equal(net.ParseIP("2001:db8::1")) {
+			t.Errorf("Dst mismatch given=%s", s.Dst)
+		}
+		if s.MAC.String() != "00:11:22:33:44:55" {
+			t.Errorf("MAC mismatch given=%s", s.MAC)
+		}
+		if s.Raw != [4]byte{10, 0, 0, 1} {
+			t.Errorf("Raw mismatch given=%v", s.Raw)
+		}
+
+		ret, err := endian.Marshal(order, s)
+		if err != nil {
+			t.Fatalf("endian.Marshal err=%s", err)
+		}
+		if bytes.Compare(ret, raw) != 0 {
+			t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+		}
+	}
+}
+
+func TestAddrError(t *testing.T) {
+	type IPv4 struct {
+		IP net.IP `endian:"ipv4"`
+	}
+	if _, err := endian.Marshal(endian.BigEndian, IPv4{IP: net.ParseIP("2001:db8::1")}); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	// nil is written as zero
+	ret, err := endian.Marshal(endian.BigEndian, IPv4{})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if expect := make([]byte, 4); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	type MAC struct {
+		MAC net.HardwareAddr `endian:"mac"`
+	}
+	if _, err := endian.Marshal(endian.BigEndian, MAC{MAC: net.HardwareAddr{0x01}}); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
@@ -0,0 +1,120 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+var (
+	bigIntType    = reflect.TypeOf(big.Int{})
+	bigIntPtrType = reflect.TypeOf(&big.Int{})
+)
+
+func isBigInt(t reflect.Type) bool {
+	return t == bigIntType || t == bigIntPtrType
+}
+
+// sizeOfBigInt returns the size of a big.Int field. size tag is required.
+func sizeOfBigInt(cnf *tagConfig) int {
+	if cnf == nil {
+		return 0
+	}
+	return cnf.size
+}
+
+// reverse returns reversed bs.
+func reverse(bs []byte) []byte {
+	ret := make([]byte, len(bs))
+	for i := range bs {
+		ret[len(bs)-1-i] = bs[i]
+	}
+	return ret
+}
+
+// readBigInt reads an integer of size bytes from b and fill a big.Int v.
+// It is two's complement if signed tag is defined.
+func readBigInt(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int) error {
+	size := sizeOfBigInt(cnf)
+	if size == 0 {
+		return fmt.Errorf("%s requires size tag", v.Type())
+	}
+	bs := b[*index : *index+size]
+	if order != BigEndian {
+		bs = reverse(bs)
+	}
+	n := new(big.Int).SetBytes(bs)
+	if cnf.signed && len(bs) > 0 && bs[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	*index += size
+
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	if v.Type() == bigIntPtrType {
+		v.Set(reflect.ValueOf(n))
+	} else {
+		v.Set(reflect.ValueOf(n).Elem())
+	}
+	return nil
+}
+
+// writeBigInt writes a big.Int v to b as an integer of size bytes.
+func writeBigInt(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int) error {
+	size := sizeOfBigInt(cnf)
+	if size == 0 {
+		return fmt.Errorf("%s requires size tag", v.Type())
+	}
+
+	var n *big.Int
+	if v.Type() == bigIntPtrType {
+		n = v.Interface().(*big.Int)
+	} else {
+		bn := v.Interface().(big.Int)
+		n = &bn
+	}
+	if n == nil {
+		/* nil is written as zero */
+		n = new(big.Int)
+	}
+
+	bits := size * 8
+	u := n
+	if cnf.signed {
+		min := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+		if n.Cmp(min) < 0 || n.BitLen() > bits-1 && n.Sign() > 0 {
+			return fmt.Errorf("%s overflows %d byte signed integer", n, size)
+		}
+		if n.Sign() < 0 {
+			u = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		}
+	} else if n.Sign() < 0 || n.BitLen() > bits {
+		return fmt.Errorf("%s overflows %d byte unsigned integer", n, size)
+	}
+
+	bs := make([]byte, size)
+	u.FillBytes(bs)
+	if order != BigEndian {
+		bs = reverse(bs)
+	}
+	copy(b[*index:*index+size], bs)
+	*index += size
+	return nil
+}
@@ -0,0 +1,326 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"reflect"
+	"unsafe"
+)
+
+// Uint16s decodes src into dst and returns the number of decoded elements.
+// It is the minimum of len(dst) and len(src)/2.
+func Uint16s(dst []uint16, src []byte, order ByteOrder) int {
+	n := minLen(len(dst), len(src), 2)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			dst[i] = order.Uint16(src[i*2:])
+		}
+		return n
+	}
+	copy(bytesOf(unsafe.Pointer(&dst[0]), n*2), src)
+	if order != NativeEndian {
+		SwapUint16s(dst[:n])
+	}
+	return n
+}
+
+// Uint32s decodes src into dst and returns the number of decoded elements.
+// It is the minimum of len(dst) and len(src)/4.
+func Uint32s(dst []uint32, src []byte, order ByteOrder) int {
+	n := minLen(len(dst), len(src), 4)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			dst[i] = order.Uint32(src[i*4:])
+		}
+		return n
+	}
+	copy(bytesOf(unsafe.Pointer(&dst[0]), n*4), src)
+	if order != NativeEndian {
+		SwapUint32s(dst[:n])
+	}
+	return n
+}
+
+// Uint64s decodes src into dst and returns the number of decoded elements.
+// It is the minimum of len(dst) and len(src)/8.
+func Uint64s(dst []uint64, src []byte, order ByteOrder) int {
+	n := minLen(len(dst), len(src), 8)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			dst[i] = order.Uint64(src[i*8:])
+		}
+		return n
+	}
+	copy(bytesOf(unsafe.Pointer(&dst[0]), n*8), src)
+	if order != NativeEndian {
+		SwapUint64s(dst[:n])
+	}
+	return n
+}
+
+// Float32s decodes src into dst and returns the number of decoded elements.
+// It is the minimum of len(dst) and len(src)/4.
+func Float32s(dst []float32, src []byte, order ByteOrder) int {
+	if len(dst) == 0 {
+		return 0
+	}
+	return Uint32s(unsafe.Slice((*uint32)(unsafe.Pointer(&dst[0])), len(dst)), src, order)
+}
+
+// Float64s decodes src into dst and returns the number of decoded elements.
+// It is the minimum of len(dst) and len(src)/8.
+func Float64s(dst []float64, src []byte, order ByteOrder) int {
+	if len(dst) == 0 {
+		return 0
+	}
+	return Uint64s(unsafe.Slice((*uint64)(unsafe.Pointer(&dst[0])), len(dst)), src, order)
+}
+
+// PutUint16s encodes src into dst and returns the number of encoded elements.
+// It is the minimum of len(dst)/2 and len(src).
+func PutUint16s(dst []byte, src []uint16, order ByteOrder) int {
+	n := minLen(len(src), len(dst), 2)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			order.PutUint16(dst[i*2:], src[i])
+		}
+		return n
+	}
+	switch {
+	case order == NativeEndian:
+		copy(dst, bytesOf(unsafe.Pointer(&src[0]), n*2))
+	case order == BigEndian:
+		for i := 0; i < n; i++ {
+			binary.BigEndian.PutUint16(dst[i*2:], src[i])
+		}
+	default:
+		for i := 0; i < n; i++ {
+			binary.LittleEndian.PutUint16(dst[i*2:], src[i])
+		}
+	}
+	return n
+}
+
+// PutUint32s encodes src into dst and returns the number of encoded elements.
+// It is the minimum of len(dst)/4 and len(src).
+func PutUint32s(dst []byte, src []uint32, order ByteOrder) int {
+	n := minLen(len(src), len(dst), 4)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			order.PutUint32(dst[i*4:], src[i])
+		}
+		return n
+	}
+	switch {
+	case order == NativeEndian:
+		copy(dst, bytesOf(unsafe.Pointer(&src[0]), n*4))
+	case order == BigEndian:
+		for i := 0; i < n; i++ {
+			binary.BigEndian.PutUint32(dst[i*4:], src[i])
+		}
+	default:
+		for i := 0; i < n; i++ {
+			binary.LittleEndian.PutUint32(dst[i*4:], src[i])
+		}
+	}
+	return n
+}
+
+// PutUint64s encodes src into dst and returns the number of encoded elements.
+// It is the minimum of len(dst)/8 and len(src).
+func PutUint64s(dst []byte, src []uint64, order ByteOrder) int {
+	n := minLen(len(src), len(dst), 8)
+	if n == 0 {
+		return 0
+	}
+	if !isStdOrder(order) {
+		for i := 0; i < n; i++ {
+			order.PutUint64(dst[i*8:], src[i])
+		}
+		return n
+	}
+	switch {
+	case order == NativeEndian:
+		copy(dst, bytesOf(unsafe.Pointer(&src[0]), n*8))
+	case order == BigEndian:
+		for i := 0; i < n; i++ {
+			binary.BigEndian.PutUint64(dst[i*8:], src[i])
+		}
+	default:
+		for i := 0; i < n; i++ {
+			binary.LittleEndian.PutUint64(dst[i*8:], src[i])
+		}
+	}
+	return n
+}
+
+// PutFloat32s encodes src into dst and returns the number of encoded elements.
+// It is the minimum of len(dst)/4 and len(src).
+func PutFloat32s(dst []byte, src []float32, order ByteOrder) int {
+	if len(src) == 0 {
+		return 0
+	}
+	return PutUint32s(dst, unsafe.Slice((*uint32)(unsafe.Pointer(&src[0])), len(src)), order)
+}
+
+// PutFloat64s encodes src into dst and returns the number of encoded elements.
+// It is the minimum of len(dst)/8 and len(src).
+func PutFloat64s(dst []byte, src []float64, order ByteOrder) int {
+	if len(src) == 0 {
+		return 0
+	}
+	return PutUint64s(dst, unsafe.Slice((*uint64)(unsafe.Pointer(&src[0])), len(src)), order)
+}
+
+// SwapUint16s reverses the byte order of each element of s in place.
+func SwapUint16s(s []uint16) {
+	for i := range s {
+		s[i] = bits.ReverseBytes16(s[i])
+	}
+}
+
+// SwapUint32s reverses the byte order of each element of s in place.
+func SwapUint32s(s []uint32) {
+	for i := range s {
+		s[i] = bits.ReverseBytes32(s[i])
+	}
+}
+
+// SwapUint64s reverses the byte order of each element of s in place.
+func SwapUint64s(s []uint64) {
+	for i := range s {
+		s[i] = bits.ReverseBytes64(s[i])
+	}
+}
+
+// minLen returns the number of elements which can be converted.
+func minLen(elems, n, size int) int {
+	if n/size < elems {
+		return n / size
+	}
+	return elems
+}
+
+// isStdOrder reports whether order is BigEndian or LittleEndian.
+// Other implementations of ByteOrder are converted element by element.
+func isStdOrder(order ByteOrder) bool {
+	return order == BigEndian || order == LittleEndian
+}
+
+// bytesOf returns n bytes from p as a byte slice.
+func bytesOf(p unsafe.Pointer, n int) []byte {
+	return unsafe.Slice((*byte)(p), n)
+}
+
+var (
+	uint16Type  = reflect.TypeOf(uint16(0))
+	uint32Type  = reflect.TypeOf(uint32(0))
+	uint64Type  = reflect.TypeOf(uint64(0))
+	float32Type = reflect.TypeOf(float32(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// bulkSlice returns v as a primitive slice like []uint32 if the bulk conversion can be used for v.
+// settable requires an array to be settable, otherwise addressable.
+func bulkSlice(v reflect.Value, settable bool, opts *Options) (interface{}, bool) {
+	t := v.Type().Elem()
+	switch t {
+	case uint16Type, uint32Type, uint64Type, float32Type, float64Type:
+	default:
+		return nil, false
+	}
+	if opts.lookupCodec(t) != nil {
+		return nil, false
+	}
+	if v.Kind() == reflect.Array && (!v.CanAddr() || (settable && !v.CanSet())) {
+		return nil, false
+	}
+	return v.Slice(0, v.Len()).Convert(reflect.SliceOf(t)).Interface(), true
+}
+
+// readBulk reads a primitive array or slice v from b at once.
+// It returns false if v is not supported or b is short.
+func readBulk(b []byte, order ByteOrder, v reflect.Value, index *int, opts *Options) bool {
+	size := v.Len() * int(v.Type().Elem().Size())
+	if len(b)-*index < size {
+		return false
+	}
+	s, ok := bulkSlice(v, true, opts)
+	if !ok {
+		return false
+	}
+	src := b[*index : *index+size]
+	switch s := s.(type) {
+	case []uint16:
+		Uint16s(s, src, order)
+	case []uint32:
+		Uint32s(s, src, order)
+	case []uint64:
+		Uint64s(s, src, order)
+	case []float32:
+		Float32s(s, src, order)
+	case []float64:
+		Float64s(s, src, order)
+	}
+	*index += size
+	return true
+}
+
+// writeBulk writes a primitive array or slice v to b at once.
+// It returns false if v is not supported or b is short.
+func writeBulk(v reflect.Value, order ByteOrder, b []byte, index *int, opts *Options) bool {
+	size := v.Len() * int(v.Type().Elem().Size())
+	if len(b)-*index < size {
+		return false
+	}
+	s, ok := bulkSlice(v, false, opts)
+	if !ok {
+		return false
+	}
+	dst := b[*index : *index+size]
+	switch s := s.(type) {
+	case []uint16:
+		PutUint16s(dst, s, order)
+	case []uint32:
+		PutUint32s(dst, s, order)
+	case []uint64:
+		PutUint64s(dst, s, order)
+	case []float32:
+		PutFloat32s(dst, s, order)
+	case []float64:
+		PutFloat64s(dst, s, order)
+	}
+	*index += size
+	return true
+}
@@ -0,0 +1,212 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/nokute78/go-endian"
+	"math"
+	"testing"
+)
+
+func TestBulk(t *testing.T) {
+	raw := make([]byte, 33)
+	for i := range raw {
+		raw[i] = byte(i*7 + 1)
+	}
+
+	for _, order := range []endian.ByteOrder{endian.BigEndian, endian.LittleEndian} {
+		u16 := make([]uint16, 20)
+		if n := endian.Uint16s(u16, raw, order); n != 16 {
+			t.Errorf("Uint16s mismatch given=%d expect=16", n)
+		}
+		u32 := make([]uint32, 8)
+		if n := endian.Uint32s(u32, raw, order); n != 8 {
+			t.Errorf("Uint32s mismatch given=%d expect=8", n)
+		}
+		u64 := make([]uint64, 2)
+		if n := endian.Uint64s(u64, raw, order); n != 2 {
+			t.Errorf("Uint64s mismatch given=%d expect=2", n)
+		}
+		f32 := make([]float32, 8)
+		endian.Float32s(f32, raw, order)
+		f64 := make([]float64, 4)
+		endian.Float64s(f64, raw, order)
+
+		for i := 0; i < 16; i++ {
+			if e := order.Uint16(raw[i*2:]); u16[i] != e {
+				t.Errorf("%s: u16[%d] given=%#x expect=%#x", order, i, u16[i], e)
+			}
+		}
+		for i := 0; i < 8; i++ {
+			if e := order.Uint32(raw[i*4:]); u32[i] != e {
+				t.Errorf("%s: u32[%d] given=%#x expect=%#x", order, i, u32[i], e)
+			}
+			if e := math.Float32frombits(order.Uint32(raw[i*4:])); math.Float32bits(f32[i]) != math.Float32bits(e) {
+				t.Errorf("%s: f32[%d] given=%v expect=%v", order, i, f32[i], e)
+			}
+		}
+		for i := 0; i < 2; i++ {
+			if e := order.Uint64(raw[i*8:]); u64[i] != e {
+				t.Errorf("%s: u64[%d] given=%#x expect=%#x", order, i, u64[i], e)
+			}
+		}
+		if u16[16] != 0 {
+			t.Errorf("%s: u16[16] is modified. given=%#x", order, u16[16])
+		}
+
+		buf := make([]byte, 32)
+		if n := endian.PutUint16s(buf, u16, order); n != 16 || bytes.Compare(buf, raw[:32]) != 0 {
+			t.Errorf("%s: PutUint16s mismatch n=%d\n given=%x\n expect=%x", order, n, buf, raw[:32])
+		}
+		buf = make([]byte, 32)
+		if n := endian.PutUint32s(buf, u32, order); n != 8 || bytes.Compare(buf, raw[:32]) != 0 {
+			t.Errorf("%s: PutUint32s mismatch n=%d\n given=%x\n expect=%x", order, n, buf, raw[:32])
+		}
+		buf = make([]byte, 12)
+		if n := endian.PutUint64s(buf, u64, order); n != 1 || bytes.Compare(buf[:8], raw[:8]) != 0 {
+			t.Errorf("%s: PutUint64s mismatch n=%d\n given=%x\n expect=%x", order, n, buf, raw[:8])
+		}
+		buf = make([]byte, 32)
+		if n := endian.PutFloat32s(buf, f32, order); n != 8 || bytes.Compare(buf, raw[:32]) != 0 {
+			t.Errorf("%s: PutFloat32s mismatch n=%d\n given=%x\n expect=%x", order, n, buf, raw[:32])
+		}
+		if n := endian.PutFloat64s(buf, f64, order); n != 4 || bytes.Compare(buf, raw[:32]) != 0 {
+			t.Errorf("%s: PutFloat64s mismatch n=%d\n given=%x\n expect=%x", order, n, buf, raw[:32])
+		}
+	}
+}
+
+func TestSwap(t *testing.T) {
+	u16 := []uint16{0x0102, 0xa0b0}
+	endian.SwapUint16s(u16)
+	if u16[0] != 0x0201 || u16[1] != 0xb0a0 {
+		t.Errorf("mismatch given=%x", u16)
+	}
+	u32 := []uint32{0x01020304}
+	endian.SwapUint32s(u32)
+	if u32[0] != 0x04030201 {
+		t.Errorf("mismatch given=%x", u32)
+	}
+	u64 := []uint64{0x0102030405060708}
+	endian.SwapUint64s(u64)
+	if u64[0] != 0x0807060504030201 {
+		t.Errorf("mismatch given=%x", u64)
+	}
+}
+
+func TestReadWriteBulk(t *testing.T) {
+	type Samples []uint32
+	type S struct {
+		A   uint8
+		U16 [3]uint16
+		U32 Samples
+		F32 [2]float32
+		F64 []float64
+	}
+
+	for _, order := range []endian.ByteOrder{endian.BigEndian, endian.LittleEndian} {
+		expect := S{
+			A:   1,
+			U16: [3]uint16{0x1234, 2, 3},
+			U32: Samples{0xdeadbeef, 5},
+			F32: [2]float32{1.5, -2},
+			F64: []float64{math.Pi, math.Inf(1)},
+		}
+		buf := &bytes.Buffer{}
+		if err := binary.Write(buf, order, expect.A); err != nil {
+			t.Fatalf("binary.Write err=%s", err)
+		}
+		for _, v := range []interface{}{expect.U16, []uint32(expect.U32), expect.F32, expect.F64} {
+			if err := binary.Write(buf, order, v); err != nil {
+				t.Fatalf("binary.Write err=%s", err)
+			}
+		}
+
+		ret, err := endian.Marshal(order, expect)
+		if err != nil {
+			t.Fatalf("endian.Marshal err=%s", err)
+		}
+		if bytes.Compare(ret, buf.Bytes()) != 0 {
+			t.Errorf("%s: mismatch\n given=%x\n expect=%x", order, ret, buf.Bytes())
+		}
+
+		s := S{U32: make(Samples, 2), F64: make([]float64, 2)}
+		if err := endian.Unmarshal(buf.Bytes(), order, &s); err != nil {
+			t.Fatalf("endian.Unmarshal err=%s", err)
+		}
+		if s.A != expect.A || s.U16 != expect.U16 || s.F32 != expect.F32 ||
+			s.U32[0] != expect.U32[0] || s.U32[1] != expect.U32[1] ||
+			s.F64[0] != expect.F64[0] || s.F64[1] != expect.F64[1] {
+			t.Errorf("%s: mismatch\n given=%+v\n expect=%+v", order, s, expect)
+		}
+	}
+}
+
+func BenchmarkReadUint32Slice(b *testing.B) {
+	s := make([]uint32, 1<<16)
+	raw := make([]byte, len(s)*4)
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+			b.Fatalf("error:%s", err)
+		}
+	}
+}
+
+func TestWriteBulkByValue(t *testing.T) {
+	type S struct {
+		U32 [4]uint32
+		F64 [2]float64
+	}
+	s := S{U32: [4]uint32{1, 2, 3, 0xdeadbeef}, F64: [2]float64{0.5, -1}}
+	expect := &bytes.Buffer{}
+	if err := binary.Write(expect, endian.BigEndian, s); err != nil {
+		t.Fatalf("binary.Write err=%s", err)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, expect.Bytes()) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect.Bytes())
+	}
+	buf := &bytes.Buffer{}
+	if err := endian.Write(buf, endian.BigEndian, s); err != nil {
+		t.Fatalf("endian.Write err=%s", err)
+	}
+	if bytes.Compare(buf.Bytes(), expect.Bytes()) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", buf.Bytes(), expect.Bytes())
+	}
+}
+
+func BenchmarkMarshalArrayByValue(b *testing.B) {
+	type S struct {
+		Samples [1 << 12]uint32
+	}
+	var s S
+	b.SetBytes(int64(len(s.Samples) * 4))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := endian.Marshal(endian.BigEndian, s); err != nil {
+			b.Fatalf("error:%s", err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Codec encodes and decodes a value of a type which is registered by RegisterCodec.
+// It is useful for types which can not have methods. e.g. types of other modules.
+type Codec interface {
+	// Size returns the size in bytes of the encoded value.
+	Size() int
+	// Decode decodes b and returns the value. len(b) is Size().
+	Decode(b []byte, order ByteOrder) (interface{}, error)
+	// Encode encodes v into b. len(b) is Size().
+	Encode(b []byte, order ByteOrder, v interface{}) error
+}
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[reflect.Type]Codec{}
+)
+
+// RegisterCodec registers c as the Codec of t.
+// Read and Write use c for values of t. If c is nil, the Codec of t is removed.
+// Options.Codecs overrides it per call.
+func RegisterCodec(t reflect.Type, c Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	defer clearPlans()
+	if c == nil {
+		delete(codecs, t)
+		return
+	}
+	codecs[t] = c
+}
+
+// lookupCodec returns the Codec of t. It returns nil if t doesn't have a Codec.
+func (o *Options) lookupCodec(t reflect.Type) Codec {
+	if c, ok := o.Codecs[t]; ok {
+		return c
+	}
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	return codecs[t]
+}
+
+// readCodec reads from b by c and fill v.
+func readCodec(b []byte, order ByteOrder, v reflect.Value, c Codec, index *int) error {
+	size := c.Size()
+	d, err := c.Decode(b[*index:*index+size], order)
+	if err != nil {
+		return err
+	}
+	*index += size
+	val := reflect.ValueOf(d)
+	if !val.IsValid() || val.Type() != v.Type() {
+		return fmt.Errorf("Codec of %s returns %T", v.Type(), d)
+	}
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.Set(val)
+	return nil
+}
+
+// writeCodec writes v to b by c.
+func writeCodec(v reflect.Value, order ByteOrder, c Codec, b []byte, index *int) error {
+	size := c.Size()
+	if err := c.Encode(b[*index:*index+size], order, v.Interface()); err != nil {
+		return err
+	}
+	*index += size
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/nokute78/go-endian"
+	"reflect"
+	"testing"
+)
+
+// version is a third party type which has no methods.
+type version struct {
+	major, minor int
+}
+
+// versionCodec encodes version as 2 uint8.
+type versionCodec struct{}
+
+func (versionCodec) Size() int {
+	return 2
+}
+
+func (versionCodec) Decode(b []byte, order endian.ByteOrder) (interface{}, error) {
+	return version{major: int(b[0]), minor: int(b[1])}, nil
+}
+
+func (versionCodec) Encode(b []byte, order endian.ByteOrder, v interface{}) error {
+	ver := v.(version)
+	if ver.major > 0xff || ver.minor > 0xff {
+		return fmt.Errorf("invalid version %v", ver)
+	}
+	b[0] = byte(ver.major)
+	b[1] = byte(ver.minor)
+	return nil
+}
+
+// swappedCodec encodes version as 2 uint8 in reverse order.
+type swappedCodec struct {
+	versionCodec
+}
+
+func (swappedCodec) Decode(b []byte, order endian.ByteOrder) (interface{}, error) {
+	return version{major: int(b[1]), minor: int(b[0])}, nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	type S struct {
+		Ver version
+		Val uint16
+	}
+	typ := reflect.TypeOf(version{})
+	endian.RegisterCodec(typ, versionCodec{})
+	defer endian.RegisterCodec(typ, nil)
+
+	raw := []byte{0x01, 0x02, 0xaa, 0xbb}
+	var s S
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if s.Ver.major != 1 || s.Ver.minor != 2 || s.Val != 0xaabb {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	s.Ver.major = 0x100
+	if _, err := endian.Marshal(endian.BigEndian, s); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	// per call override
+	opts := &endian.Options{Codecs: map[reflect.Type]endian.Codec{typ: swappedCodec{}}}
+	if err := endian.UnmarshalWithOptions(raw, endian.BigEndian, &s, opts); err != nil {
+		t.Fatalf("endian.UnmarshalWithOptions err=%s", err)
+	}
+	if s.Ver.major != 2 || s.Ver.minor != 1 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+}
+
+// codecName is a string type which is encoded as 4 byte fixed string by nameCodec.
+type codecName string
+
+type nameCodec struct{}
+
+func (nameCodec) Size() int {
+	return 4
+}
+
+func (nameCodec) Decode(b []byte, order endian.ByteOrder) (interface{}, error) {
+	return codecName(bytes.TrimRight(b, " ")), nil
+}
+
+func (nameCodec) Encode(b []byte, order endian.ByteOrder, v interface{}) error {
+	copy(b, "    ")
+	copy(b, v.(codecName))
+	return nil
+}
+
+func TestRegisterCodecString(t *testing.T) {
+	type S struct {
+		Name codecName
+		Val  uint8
+	}
+	typ := reflect.TypeOf(codecName(""))
+	endian.RegisterCodec(typ, nameCodec{})
+	defer endian.RegisterCodec(typ, nil)
+
+	raw := []byte{'a', 'b', ' ', ' ', 0x01}
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Name != "ab" || s.Val != 1 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Marshal(endian.BigEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
@@ -17,23 +17,37 @@
 package endian
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 )
 
 var errCannotInterface = errors.New("CanInterface returns false")
 
+// ErrUnexportedField is returned in strict mode if a struct has an unexported field.
+var ErrUnexportedField = errors.New("unexported field")
+
 // read reads from b and fill v.
-func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
+func read(b []byte, order ByteOrder, v reflect.Value, index *int, opts *Options) error {
 	var val reflect.Value
 	if !v.CanInterface() {
 		// skip unexported field
-		*index += sizeOfValue(v, false)
+		*index += sizeOfValue(v, true, opts)
 		return errCannotInterface
 	}
+	if c := opts.lookupCodec(v.Type()); c != nil {
+		return readCodec(b, order, v, c, index)
+	}
+	if v.Type() == timeType {
+		return readTime(b, order, v, nil, index)
+	}
+	if isBigInt(v.Type()) {
+		return readBigInt(b, order, v, nil, index)
+	}
 	d := v.Interface()
 
 	switch d.(type) {
@@ -49,6 +63,12 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 	case uint64:
 		val = reflect.ValueOf(order.Uint64(b[*index : *index+8]))
 		*index += 8
+	case float32:
+		val = reflect.ValueOf(math.Float32frombits(order.Uint32(b[*index : *index+4])))
+		*index += 4
+	case float64:
+		val = reflect.ValueOf(math.Float64frombits(order.Uint64(b[*index : *index+8])))
+		*index += 8
 	default: /* other data types */
 		switch v.Kind() {
 		case reflect.Array, reflect.Slice:
@@ -56,7 +76,7 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 				if v.Index(0).Kind() == reflect.Uint8 {
 					length := v.Len()
 					ret := b[*index : *index+length]
-					if order == BigEndian {
+					if opts.reverseBytes(order) {
 						for i := 0; i < length; i++ {
 							if v.Index(i).CanSet() {
 								// workaround! binary.Read doesn't support []byte in BigEndian
@@ -72,9 +92,12 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 					}
 					*index += length
 					return nil
+				} else if readBulk(b, order, v, index, opts) {
+					/* primitive slice/array */
+					return nil
 				} else {
 					for i := 0; i < v.Len(); i++ {
-						err := read(b, order, v.Index(i), index)
+						err := read(b, order, v.Index(i), index, opts)
 						if err != nil && err != errCannotInterface {
 							return err
 						}
@@ -83,36 +106,9 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 				}
 			}
 		case reflect.Struct:
-			for i := 0; i < v.Type().NumField(); i++ {
-				f := v.Type().Field(i)
-				cnf := parseStructTag(f.Tag)
-				if cnf != nil {
-					/* struct tag is defined */
-					if cnf.ignore {
-						continue
-					} else if cnf.skip {
-						/* only updates offset. not fill. */
-						*index = sizeOfValue(v.Field(i), true)
-						continue
-					} else if cnf.endian != Endian_Type_BLANK {
-						var err error
-						if cnf.endian == Endian_Type_BE {
-							err = read(b, BigEndian, v.Field(i), index)
-						} else {
-							err = read(b, LittleEndian, v.Field(i), index)
-						}
-						if err != nil && err != errCannotInterface {
-							return err
-						}
-						continue
-					}
-				}
-				err := read(b, order, v.Field(i), index)
-				if err != nil && err != errCannotInterface {
-					return err
-				}
-			}
-			return nil
+			return readStruct(b, order, v, index, opts)
+		case reflect.String:
+			return readString(b, order, v, nil, index, opts)
 		default:
 			return fmt.Errorf("Not Supported %s", v.Kind())
 		}
@@ -127,6 +123,86 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 	return nil
 }
 
+// readStruct reads fields of a struct v from b.
+func readStruct(b []byte, order ByteOrder, v reflect.Value, index *int, opts *Options) error {
+	for i := 0; i < v.Type().NumField(); i++ {
+		f := v.Type().Field(i)
+		cnf, err := parseStructTag(f.Tag)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+		}
+		if cnf != nil {
+			/* struct tag is defined */
+			if cnf.ignore {
+				continue
+			} else if cnf.skipped() {
+				/* only updates offset. not fill. */
+				*index += sizeOfField(fieldValue(v, i), cnf, opts)
+				continue
+			}
+		}
+		fv := v.Field(i)
+		if opts.Unexported && f.PkgPath != "" && f.Name != "_" {
+			fv = exportField(fv)
+		} else if opts.Strict && isUnexported(f) {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, ErrUnexportedField)
+		}
+		if cnf != nil && cnf.rest && i != v.NumField()-1 {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, errRestNotLast)
+		}
+		if isEmbeddedStruct(f) && opts.lookupCodec(f.Type) == nil {
+			/* flatten embedded struct. the struct tag of the embedding site is inherited. */
+			ev, err := embeddedStruct(fv, true)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+			}
+			err = readStruct(b, cnf.byteOrder(order), ev, index, opts.withTag(cnf))
+			if err != nil && err != errCannotInterface {
+				return err
+			}
+			continue
+		}
+		err = readField(b, cnf.byteOrder(order), fv, cnf, index, opts.withTag(cnf))
+		if err != nil && err != errCannotInterface {
+			return err
+		}
+	}
+	return nil
+}
+
+// readField reads a struct field v which has the struct tag cnf.
+func readField(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int, opts *Options) error {
+	if !v.CanInterface() {
+		// skip unexported field
+		*index += sizeOfField(v, cnf, opts)
+		return errCannotInterface
+	}
+	if c := opts.lookupCodec(v.Type()); c != nil {
+		/* registered codec takes precedence over struct tags */
+		return readCodec(b, order, v, c, index)
+	}
+
+	switch {
+	case cnf != nil && cnf.rest:
+		return readRest(b, v, index)
+	case cnf.hasScale():
+		return readScaled(b, order, v, cnf, index)
+	case cnf.hasFloat():
+		return readFloat(b, order, v, cnf, index)
+	case cnf.hasDigit():
+		return readDigit(b, order, v, cnf, index)
+	case isBigInt(v.Type()):
+		return readBigInt(b, order, v, cnf, index)
+	case cnf.hasAddr():
+		return readAddr(b, v, cnf, index)
+	case v.Type() == timeType && cnf.hasTime():
+		return readTime(b, order, v, cnf, index)
+	case v.Kind() == reflect.String:
+		return readString(b, order, v, cnf, index, opts)
+	}
+	return read(b, order, v, index, opts)
+}
+
 // Read reads structured binary data from i into data.
 // Data must be a pointer to a fixed-size value.
 // Not exported struct field is ignored.
@@ -134,24 +210,119 @@ func read(b []byte, order ByteOrder, v reflect.Value, index *int) error {
 //       `bit:"skip"` : ignore the field. Skip X bits which is the size of the field. It is useful for reserved field.
 //       `bit:"-"`    : ignore the field. Offset is not changed.
 func Read(r io.Reader, order ByteOrder, data interface{}) error {
+	return ReadWithOptions(r, order, data, nil)
+}
+
+// ReadWithOptions is like Read but the behavior is customized by opts.
+// If opts is nil, it is same as Read.
+func ReadWithOptions(r io.Reader, order ByteOrder, data interface{}, opts *Options) error {
+	opts = optionsOrDefault(opts)
 	v := reflect.ValueOf(data)
 	switch v.Kind() {
 	case reflect.Ptr:
-		c := sizeOfValue(reflect.Indirect(v), true)
-		barr := make([]byte, c)
-		n, err := r.Read(barr)
-		if err != nil {
+		var barr []byte
+		var err error
+		if err := checkRest(v.Type().Elem(), true, opts); err != nil {
 			return err
-		} else if n != c {
-			return fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", c, n)
 		}
-		index := 0
-		err = read(barr, order, reflect.Indirect(v), &index)
-		if err != io.EOF && err != errCannotInterface {
-			return err
+		if clearRest(reflect.Indirect(v)) {
+			/* rest field consumes all remaining input */
+			barr, err = readAll(r, opts)
+			if err != nil {
+				return err
+			}
+		} else {
+			if hasVarString(v.Type().Elem(), map[reflect.Type]bool{}) {
+				clearVarString(reflect.Indirect(v))
+			}
+			c := sizeOfValue(reflect.Indirect(v), true, opts)
+			if err := opts.checkAlloc(c); err != nil {
+				return err
+			}
+			barr = make([]byte, c)
+			n, err := r.Read(barr)
+			if err != nil {
+				return err
+			} else if n != c {
+				return fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", c, n)
+			}
+		}
+		for {
+			/* variable length strings may require more bytes */
+			need, err := decode(barr, order, reflect.Indirect(v), opts)
+			if err != nil || need == 0 {
+				return err
+			}
+			if err := opts.checkAlloc(need); err != nil {
+				return err
+			}
+			more := make([]byte, need-len(barr))
+			if n, err := io.ReadFull(r, more); err != nil {
+				return fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", need, len(barr)+n)
+			}
+			barr = append(barr, more...)
 		}
 	default:
 		return binary.Read(r, order, data)
 	}
+}
+
+// Unmarshal is like Read but reads from b.
+// A field which has `endian:"rest"` consumes all remaining bytes of b.
+func Unmarshal(b []byte, order ByteOrder, data interface{}) error {
+	return UnmarshalWithOptions(b, order, data, nil)
+}
+
+// UnmarshalWithOptions is like Unmarshal but the behavior is customized by opts.
+// If opts is nil, it is same as Unmarshal.
+func UnmarshalWithOptions(b []byte, order ByteOrder, data interface{}, opts *Options) error {
+	opts = optionsOrDefault(opts)
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if err := checkRest(v.Type().Elem(), true, opts); err != nil {
+			return err
+		}
+		clearRest(reflect.Indirect(v))
+		if hasVarString(v.Type().Elem(), map[reflect.Type]bool{}) {
+			/* the size of the current value must not exceed the input */
+			clearVarString(reflect.Indirect(v))
+		}
+		return unmarshal(b, order, reflect.Indirect(v), opts)
+	default:
+		return binary.Read(bytes.NewReader(b), order, data)
+	}
+}
+
+// unmarshal reads from b and fill v.
+func unmarshal(b []byte, order ByteOrder, v reflect.Value, opts *Options) error {
+	need, err := decode(b, order, v, opts)
+	if err != nil {
+		return err
+	}
+	if need > 0 {
+		return fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", need, len(b))
+	}
 	return nil
 }
+
+// decode reads from b and fill v.
+// It returns the required size if b is shorter than v.
+// v is decoded again if a variable length string is resized since following offsets are changed.
+func decode(b []byte, order ByteOrder, v reflect.Value, opts *Options) (int, error) {
+	for {
+		c := sizeOfValue(v, true, opts)
+		if len(b) < c {
+			return c, nil
+		}
+		index := 0
+		err := read(b, order, v, &index, opts)
+		if err == errStringResized {
+			continue
+		}
+		if err != io.EOF && err != errCannotInterface {
+			return 0, err
+		}
+		return 0, nil
+	}
+}
@@ -111,6 +111,24 @@ func TestReadStruct(t *testing.T) {
 	}
 }
 
+func TestUnmarshal(t *testing.T) {
+	type Sample struct {
+		Header byte
+		Val    uint16
+	}
+	s := Sample{}
+	if err := endian.Unmarshal([]byte{0x7f, 0x01, 0x02, 0xff}, endian.BigEndian, &s); err != nil {
+		t.Fatalf("error:%s", err)
+	}
+	if s.Header != 0x7f || s.Val != 0x0102 {
+		t.Errorf("mismatch: given=%+v", s)
+	}
+
+	if err := endian.Unmarshal([]byte{0x7f, 0x01}, endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
 func TestStructTag(t *testing.T) {
 	type Sample struct {
 		Reserved byte `endian:"-"` // ignored
@@ -151,6 +169,47 @@ func TestStructTag(t *testing.T) {
 	if s2.Val != 0xaa {
 		t.Errorf("given=0x%x expect=0xaa", s2.Val)
 	}
+
+	// skip after other fields
+	type Sample3 struct {
+		Val      byte
+		Reserved [2]byte `endian:"skip"`
+		Val2     byte
+	}
+
+	s3 := Sample3{}
+	br = bytes.NewReader([]byte{0x11, 0xff, 0xff, 0x22})
+	if err := endian.Read(br, endian.LittleEndian, &s3); err != nil {
+		t.Fatalf("error:%s\n", err)
+	}
+	if s3.Val != 0x11 || s3.Val2 != 0x22 {
+		t.Errorf("given=%+v expect Val=0x11 Val2=0x22", s3)
+	}
+}
+
+func TestSkipKeep(t *testing.T) {
+	type Header struct {
+		Magic    uint32
+		Reserved [6]byte `endian:"skip,keep"`
+		Flags    uint16
+	}
+
+	raw := []byte{0x7f, 0x45, 0x4c, 0x46, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0xaa, 0xbb}
+	h := Header{}
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &h); err != nil {
+		t.Fatalf("endian.Read:%s", err)
+	}
+	if expect := raw[4:10]; bytes.Compare(h.Reserved[:], expect) != 0 {
+		t.Errorf("Reserved mismatch: given=%x expect=%x", h.Reserved, expect)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := endian.WriteWithOptions(buf, endian.BigEndian, h, &endian.Options{SkipFill: 0xff}); err != nil {
+		t.Fatalf("endian.Write:%s", err)
+	}
+	if ret := buf.Bytes(); bytes.Compare(ret, raw) != 0 {
+		t.Errorf("round trip mismatch: given=%x expect=%x", ret, raw)
+	}
 }
 
 func TestReadArray(t *testing.T) {
@@ -202,6 +261,73 @@ func TestMixedEndian(t *testing.T) {
 	}
 }
 
+func TestNativeEndian(t *testing.T) {
+	type Data struct {
+		F1 uint32 `endian:"native"`
+		F2 uint16 `endian:"native"`
+	}
+
+	raw := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	d := Data{}
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &d); err != nil {
+		t.Fatalf("endian.Read:%s", err)
+	}
+
+	if expect := endian.NativeEndian.Uint32(raw[0:4]); d.F1 != expect {
+		t.Errorf("F1 mismatch:given=0x%x expect=0x%x", d.F1, expect)
+	}
+	if expect := endian.NativeEndian.Uint16(raw[4:6]); d.F2 != expect {
+		t.Errorf("F2 mismatch:given=0x%x expect=0x%x", d.F2, expect)
+	}
+}
+
+func TestReadRawBytes(t *testing.T) {
+	type Data struct {
+		Len  uint16
+		MAC  [6]byte `endian:"raw"`
+		Hash [4]byte `endian:"bytes"`
+		Val  [2]byte
+	}
+
+	raw := []byte{0x00, 0x06, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0xde, 0xad, 0xbe, 0xef, 0x01, 0x02}
+	d := Data{}
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &d); err != nil {
+		t.Fatalf("endian.Read:%s", err)
+	}
+	if d.Len != 6 {
+		t.Errorf("Len mismatch:given=%d expect=6", d.Len)
+	}
+	if expect := raw[2:8]; bytes.Compare(d.MAC[:], expect) != 0 {
+		t.Errorf("MAC mismatch:given=%x expect=%x", d.MAC, expect)
+	}
+	if expect := raw[8:12]; bytes.Compare(d.Hash[:], expect) != 0 {
+		t.Errorf("Hash mismatch:given=%x expect=%x", d.Hash, expect)
+	}
+	if expect := []byte{0x02, 0x01}; bytes.Compare(d.Val[:], expect) != 0 {
+		t.Errorf("Val mismatch:given=%x expect=%x", d.Val, expect)
+	}
+}
+
+func TestReadWithOptionsByteArray(t *testing.T) {
+	type Data struct {
+		Name [4]byte
+		Val  [2]byte `endian:"reverse"`
+	}
+
+	raw := []byte{'n', 'a', 'm', 'e', 0x01, 0x02}
+	d := Data{}
+	opts := &endian.Options{ByteArray: endian.ByteArrayRaw}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.BigEndian, &d, opts); err != nil {
+		t.Fatalf("endian.ReadWithOptions:%s", err)
+	}
+	if string(d.Name[:]) != "name" {
+		t.Errorf("Name mismatch:given=%q expect=%q", d.Name, "name")
+	}
+	if expect := []byte{0x02, 0x01}; bytes.Compare(d.Val[:], expect) != 0 {
+		t.Errorf("Val mismatch:given=%x expect=%x", d.Val, expect)
+	}
+}
+
 func BenchmarkReadStruct(b *testing.B) {
 	type Sample struct {
 		Header   byte
@@ -0,0 +1,241 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const (
+	Digit_Type_BLANK = iota
+	Digit_Type_BCD
+	Digit_Type_OCT
+	Digit_Type_DEC
+	Digit_Type_HEX
+)
+
+var asciiTypes = map[string]int{
+	"oct": Digit_Type_OCT,
+	"dec": Digit_Type_DEC,
+	"hex": Digit_Type_HEX,
+}
+
+// DigitError is returned if a bcd or ascii field has an invalid digit.
+type DigitError struct {
+	Encoding string // "bcd", "ascii=oct", "ascii=dec" or "ascii=hex"
+	Offset   int    // offset of the invalid byte in the input
+	Value    byte   // the invalid byte
+}
+
+func (e *DigitError) Error() string {
+	return fmt.Sprintf("invalid digit 0x%02x of %s at offset %d", e.Value, e.Encoding, e.Offset)
+}
+
+// hasDigit reports whether the tag has bcd or ascii.
+func (c *tagConfig) hasDigit() bool {
+	return c != nil && c.digit != Digit_Type_BLANK
+}
+
+func (c *tagConfig) digitName() string {
+	switch c.digit {
+	case Digit_Type_BCD:
+		return "bcd"
+	case Digit_Type_OCT:
+		return "ascii=oct"
+	case Digit_Type_DEC:
+		return "ascii=dec"
+	case Digit_Type_HEX:
+		return "ascii=hex"
+	}
+	return ""
+}
+
+func (c *tagConfig) digitBase() int {
+	switch c.digit {
+	case Digit_Type_OCT:
+		return 8
+	case Digit_Type_HEX:
+		return 16
+	}
+	return 10
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// sizeOfDigit returns the size of a bcd or ascii field.
+// The size of bcd is the size of the field if size tag is not defined.
+func sizeOfDigit(v reflect.Value, cnf *tagConfig) int {
+	if cnf.size > 0 {
+		return cnf.size
+	}
+	if cnf.digit == Digit_Type_BCD && isInteger(v.Kind()) {
+		return v.Type().Bits() / 8
+	}
+	return 0
+}
+
+// bcdBytes returns bs in big endian order.
+func bcdBytes(bs []byte, order ByteOrder) []byte {
+	ret := make([]byte, len(bs))
+	copy(ret, bs)
+	if order == LittleEndian {
+		for i := 0; i < len(ret)/2; i++ {
+			ret[i], ret[len(ret)-1-i] = ret[len(ret)-1-i], ret[i]
+		}
+	}
+	return ret
+}
+
+// parseDigit parses bs as bcd or ascii number.
+func parseDigit(bs []byte, order ByteOrder, cnf *tagConfig, offset int) (uint64, error) {
+	var ret uint64
+	if cnf.digit == Digit_Type_BCD {
+		be := bcdBytes(bs, order)
+		for i, c := range be {
+			if c>>4 > 9 || c&0xf > 9 {
+				off := offset + i
+				if order == LittleEndian {
+					off = offset + len(be) - 1 - i
+				}
+				return 0, &DigitError{Encoding: cnf.digitName(), Offset: off, Value: c}
+			}
+			ret = ret*100 + uint64(c>>4)*10 + uint64(c&0xf)
+		}
+		return ret, nil
+	}
+
+	/* ascii. leading/trailing spaces and NULs are ignored */
+	start, end := 0, len(bs)
+	for start < end && (bs[start] == ' ' || bs[start] == 0) {
+		start++
+	}
+	for end > start && (bs[end-1] == ' ' || bs[end-1] == 0) {
+		end--
+	}
+	if start == end {
+		return 0, nil
+	}
+	for i := start; i < end; i++ {
+		if _, err := strconv.ParseUint(string(bs[i]), cnf.digitBase(), 8); err != nil {
+			return 0, &DigitError{Encoding: cnf.digitName(), Offset: offset + i, Value: bs[i]}
+		}
+	}
+	return strconv.ParseUint(string(bs[start:end]), cnf.digitBase(), 64)
+}
+
+func errDigitKind(v reflect.Value, cnf *tagConfig) error {
+	return fmt.Errorf("Not Supported %s with %s, it must be integer", v.Type(), cnf.digitName())
+}
+
+// readDigit reads a bcd or ascii number from b and fill an integer v.
+func readDigit(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int) error {
+	if !isInteger(v.Kind()) {
+		return errDigitKind(v, cnf)
+	}
+	size := sizeOfDigit(v, cnf)
+	if size == 0 {
+		return fmt.Errorf("%s requires size tag", cnf.digitName())
+	}
+	u, err := parseDigit(b[*index:*index+size], order, cnf, *index)
+	if err != nil {
+		return err
+	}
+	*index += size
+
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if u > 1<<63-1 || v.OverflowInt(int64(u)) {
+			return fmt.Errorf("%d overflows %s", u, v.Type())
+		}
+		v.SetInt(int64(u))
+	default:
+		if v.OverflowUint(u) {
+			return fmt.Errorf("%d overflows %s", u, v.Type())
+		}
+		v.SetUint(u)
+	}
+	return nil
+}
+
+// writeDigit writes an integer v to b as a bcd or ascii number.
+// ascii is padded by '0' or ' ' if pad=space.
+func writeDigit(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int) error {
+	if !isInteger(v.Kind()) {
+		return errDigitKind(v, cnf)
+	}
+	size := sizeOfDigit(v, cnf)
+	if size == 0 {
+		return fmt.Errorf("%s requires size tag", cnf.digitName())
+	}
+
+	var u uint64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return fmt.Errorf("negative value %d can not be %s", v.Int(), cnf.digitName())
+		}
+		u = uint64(v.Int())
+	default:
+		u = v.Uint()
+	}
+
+	bs := b[*index : *index+size]
+	if cnf.digit == Digit_Type_BCD {
+		be := make([]byte, size)
+		n := u
+		for i := size - 1; i >= 0; i-- {
+			be[i] = byte(n%10) | byte(n/10%10)<<4
+			n /= 100
+		}
+		if n != 0 {
+			return fmt.Errorf("%d overflows %d byte %s", u, size, cnf.digitName())
+		}
+		copy(bs, bcdBytes(be, order))
+	} else {
+		width := size
+		if cnf.nulTerm {
+			width--
+			bs[width] = 0
+		}
+		str := strconv.FormatUint(u, cnf.digitBase())
+		if len(str) > width {
+			return fmt.Errorf("%d overflows %d byte %s", u, size, cnf.digitName())
+		}
+		pad := byte('0')
+		if cnf.spacePad {
+			pad = ' '
+		}
+		for i := 0; i < width-len(str); i++ {
+			bs[i] = pad
+		}
+		copy(bs[width-len(str):width], str)
+	}
+	*index += size
+	return nil
+}
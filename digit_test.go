@@ -0,0 +1,112 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+func TestBCD(t *testing.T) {
+	type RTC struct {
+		Sec  uint8  `endian:"bcd"`
+		Year uint16 `endian:"bcd"`
+		Num  uint32 `endian:"bcd,size=3,LE"`
+	}
+	raw := []byte{0x59, 0x20, 0x24, 0x56, 0x34, 0x12}
+
+	var r RTC
+	if err := endian.Unmarshal(raw, endian.BigEndian, &r); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if r.Sec != 59 || r.Year != 2024 || r.Num != 123456 {
+		t.Errorf("mismatch given=%+v", r)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, r)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	r.Sec = 100
+	if _, err := endian.Marshal(endian.BigEndian, r); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestASCII(t *testing.T) {
+	type Header struct {
+		Mode  uint32 `endian:"ascii=oct,size=8,nul"`
+		Size  int64  `endian:"ascii=oct,size=12,pad=space"`
+		Inode uint32 `endian:"ascii=hex,size=8"`
+		Num   uint16 `endian:"ascii=dec,size=4"`
+	}
+	raw := []byte("0000644\x00" + "        1750" + "0000ABCD" + "0042")
+
+	var h Header
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &h); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if h.Mode != 0644 || h.Size != 1000 || h.Inode != 0xabcd || h.Num != 42 {
+		t.Errorf("mismatch given=%+v", h)
+	}
+
+	ret, err := endian.Marshal(endian.LittleEndian, h)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	expect := []byte("0000644\x00" + "        1750" + "0000abcd" + "0042")
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%q\n expect=%q", ret, expect)
+	}
+
+	h.Num = 10000
+	if _, err := endian.Marshal(endian.LittleEndian, h); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestDigitError(t *testing.T) {
+	type S struct {
+		A   byte
+		BCD uint16 `endian:"bcd"`
+		Oct uint16 `endian:"ascii=oct,size=3"`
+	}
+
+	var s S
+	var derr *endian.DigitError
+	err := endian.Unmarshal([]byte{0x00, 0x12, 0x3a, '0', '0', '0'}, endian.BigEndian, &s)
+	if !errors.As(err, &derr) {
+		t.Fatalf("DigitError is not returned. err=%v", err)
+	}
+	if derr.Offset != 2 || derr.Value != 0x3a || derr.Encoding != "bcd" {
+		t.Errorf("mismatch given=%+v", derr)
+	}
+
+	err = endian.Unmarshal([]byte{0x00, 0x12, 0x34, '0', '8', '0'}, endian.BigEndian, &s)
+	if !errors.As(err, &derr) {
+		t.Fatalf("DigitError is not returned. err=%v", err)
+	}
+	if derr.Offset != 4 || derr.Value != '8' || derr.Encoding != "ascii=oct" {
+		t.Errorf("mismatch given=%+v", derr)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const dumpWidth = 16
+
+// Dump decodes data into v and writes a hexdump of data to w.
+// Each line is annotated with the field path, the Go type, the byte order and the decoded value.
+// skip fields are shown as gaps and bytes after v are shown as trailing.
+// Even if decoding fails, the dump is written to find which field is wrong and the error is returned.
+func Dump(w io.Writer, data []byte, order ByteOrder, v interface{}) error {
+	return DumpWithOptions(w, data, order, v, nil)
+}
+
+// DumpWithOptions is like Dump but the behavior is customized by opts.
+// If opts is nil, it is same as Dump.
+func DumpWithOptions(w io.Writer, data []byte, order ByteOrder, v interface{}, opts *Options) error {
+	derr := UnmarshalWithOptions(data, order, v, opts)
+	l, err := LayoutOfWithOptions(v, opts)
+	if err != nil {
+		return err
+	}
+	d := &dumper{w: w, data: data}
+	d.dump(l, "", order)
+	if l.Size < len(data) {
+		d.lines(l.Size, len(data)-l.Size, "(trailing)")
+	}
+	if d.err != nil {
+		return d.err
+	}
+	return derr
+}
+
+type dumper struct {
+	w    io.Writer
+	data []byte
+	err  error
+}
+
+// dump writes the leaves of l. path is the field path of l.
+func (d *dumper) dump(l *Layout, path string, order ByteOrder) {
+	if l.Order != nil {
+		order = l.Order
+	}
+	switch {
+	case l.Ignore:
+		return
+	case l.Skip && !l.Keep:
+		d.lines(l.Offset, l.Size, path+" (skip)")
+		return
+	case len(l.Fields) > 0:
+		for _, f := range l.Fields {
+			d.dump(f, joinPath(path, f.Name), order)
+		}
+		return
+	}
+	if path == "" {
+		path = l.Type.String()
+	}
+	d.lines(l.Offset, l.Size, fmt.Sprintf("%s %s %s = %s", path, l.Type, orderName(order), formatValue(l.value)))
+}
+
+// lines writes size bytes from offset as hexdump lines. note is written to the first line.
+func (d *dumper) lines(offset, size int, note string) {
+	for i := 0; i == 0 || i < size; i += dumpWidth {
+		n := size - i
+		if n > dumpWidth {
+			n = dumpWidth
+		}
+		hex := make([]string, n)
+		for j := range hex {
+			if p := offset + i + j; p < len(d.data) {
+				hex[j] = fmt.Sprintf("%02x", d.data[p])
+			} else {
+				hex[j] = "??"
+			}
+		}
+		d.printf("%04x  %-*s  %s\n", offset+i, dumpWidth*3-1, strings.Join(hex, " "), note)
+		note = ""
+	}
+}
+
+func (d *dumper) printf(format string, a ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, a...)
+}
+
+// joinPath returns the path of the field name in path.
+func joinPath(path, name string) string {
+	if path == "" || strings.HasPrefix(name, "[") {
+		return path + name
+	}
+	return path + "." + name
+}
+
+// formatValue returns the decoded value as a string.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if !v.CanInterface() {
+		return "<unexported>"
+	}
+	i := v.Interface()
+	if _, ok := i.(fmt.Stringer); ok {
+		return fmt.Sprintf("%v", i)
+	}
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%x", i)
+		}
+	case reflect.String:
+		return fmt.Sprintf("%q", i)
+	}
+	return fmt.Sprintf("%v", i)
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	type Entry struct {
+		Flags uint16
+		Pad   [2]byte `endian:"skip"`
+	}
+	type S struct {
+		Magic   uint32 `endian:"BE"`
+		Entries [2]Entry
+		Name    string `endian:"size=4"`
+	}
+	raw := []byte{
+		0xca, 0xfe, 0xba, 0xbe,
+		0x01, 0x00, 0xff, 0xff,
+		0x02, 0x00, 0xff, 0xff,
+		'a', 'b', 0, 0,
+		0x99,
+	}
+
+	var s S
+	buf := &bytes.Buffer{}
+	if err := endian.Dump(buf, raw, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Dump err=%s", err)
+	}
+	if s.Magic != 0xcafebabe || s.Entries[1].Flags != 2 || s.Name != "ab" {
+		t.Errorf("decode mismatch given=%+v", s)
+	}
+
+	expect := []string{
+		"0000  ca fe ba be" + strings.Repeat(" ", 36) + "  Magic uint32 BE = 3405691582",
+		"0004  01 00" + strings.Repeat(" ", 42) + "  Entries[0].Flags uint16 LE = 1",
+		"0006  ff ff" + strings.Repeat(" ", 42) + "  Entries[0].Pad (skip)",
+		"0008  02 00" + strings.Repeat(" ", 42) + "  Entries[1].Flags uint16 LE = 2",
+		"000a  ff ff" + strings.Repeat(" ", 42) + "  Entries[1].Pad (skip)",
+		"000c  61 62 00 00" + strings.Repeat(" ", 36) + "  Name string LE = \"ab\"",
+		"0010  99" + strings.Repeat(" ", 45) + "  (trailing)",
+	}
+	given := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(given) != len(expect) {
+		t.Fatalf("mismatch\n given=\n%s", buf.String())
+	}
+	for i := range expect {
+		if given[i] != expect[i] {
+			t.Errorf("%d: mismatch\n given=%q\n expect=%q", i, given[i], expect[i])
+		}
+	}
+}
+
+func TestDumpShort(t *testing.T) {
+	type S struct {
+		A uint32
+		B [20]byte
+	}
+	buf := &bytes.Buffer{}
+	if err := endian.Dump(buf, []byte{1, 2, 3, 4, 5}, endian.BigEndian, &S{}); err == nil {
+		t.Errorf("error is not returned")
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("mismatch\n given=\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "0004  05 ?? ??") || !strings.HasPrefix(lines[2], "0014  ?? ?? ?? ??  ") {
+		t.Errorf("mismatch\n given=\n%s", buf.String())
+	}
+}
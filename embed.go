@@ -0,0 +1,106 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errRecursiveEmbedded is returned if a struct embeds a pointer to itself directly or indirectly.
+// The flattened fields would be infinite.
+var errRecursiveEmbedded = errors.New("recursive embedded struct")
+
+// isEmbeddedStruct reports whether f is an embedded struct or an embedded pointer to struct.
+func isEmbeddedStruct(f reflect.StructField) bool {
+	if !f.Anonymous {
+		return false
+	}
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// embeddedStruct returns the struct value of an embedded field v.
+// If v is a nil pointer, it is allocated if alloc is true.
+// Otherwise zero value is returned.
+func embeddedStruct(v reflect.Value, alloc bool) (reflect.Value, error) {
+	if v.Kind() != reflect.Ptr {
+		return v, nil
+	}
+	if v.IsNil() {
+		if !alloc {
+			return reflect.Zero(v.Type().Elem()), nil
+		}
+		if !v.CanSet() {
+			return reflect.Value{}, fmt.Errorf("can not set nil pointer to unexported embedded struct %s", v.Type().Elem())
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return v.Elem(), nil
+}
+
+// fieldValue returns i-th field of a struct v.
+// An embedded pointer is dereferenced to compute the size.
+func fieldValue(v reflect.Value, i int) reflect.Value {
+	fv := v.Field(i)
+	if isEmbeddedStruct(v.Type().Field(i)) {
+		fv, _ = embeddedStruct(fv, false)
+	}
+	return fv
+}
+
+// checkEmbedded returns errRecursiveEmbedded if t has an embedded pointer to a struct which contains the pointer.
+func checkEmbedded(t reflect.Type, opts *Options) error {
+	return checkEmbeddedRecursive(t, opts, map[reflect.Type]bool{}, map[reflect.Type]bool{})
+}
+
+// checkEmbeddedRecursive checks t. stack has the structs which are being checked.
+func checkEmbeddedRecursive(t reflect.Type, opts *Options, stack, done map[reflect.Type]bool) error {
+	if done[t] || opts.lookupCodec(t) != nil || t == timeType || isBigInt(t) {
+		return nil
+	}
+	if stack[t] {
+		return errRecursiveEmbedded
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice:
+		return checkEmbeddedRecursive(t.Elem(), opts, stack, done)
+	case reflect.Struct:
+		stack[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			cnf, _ := parseStructTag(f.Tag)
+			if cnf != nil && cnf.ignore || cnf.encoded() {
+				continue
+			}
+			ft := f.Type
+			if isEmbeddedStruct(f) && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if err := checkEmbeddedRecursive(ft, opts, stack, done); err != nil {
+				return fmt.Errorf("%s.%s: %w", t, f.Name, err)
+			}
+		}
+		delete(stack, t)
+	}
+	done[t] = true
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type Header struct {
+	Type uint8
+	Len  uint16
+}
+
+type header struct {
+	Type uint8
+	Len  uint16
+}
+
+func TestEmbeddedStruct(t *testing.T) {
+	type Msg struct {
+		Header
+		Val uint16
+	}
+	raw := []byte{0x01, 0x00, 0x02, 0xaa, 0xbb}
+
+	var m Msg
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &m); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if m.Type != 0x01 || m.Len != 0x0002 || m.Val != 0xaabb {
+		t.Errorf("mismatch given=%+v", m)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, m)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestEmbeddedStructTag(t *testing.T) {
+	type Msg struct {
+		Header `endian:"LE"`
+		Val    uint16
+	}
+	raw := []byte{0x01, 0x02, 0x00, 0xaa, 0xbb}
+
+	var m Msg
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &m); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if m.Type != 0x01 || m.Len != 0x0002 || m.Val != 0xaabb {
+		t.Errorf("mismatch given=%+v", m)
+	}
+}
+
+func TestEmbeddedUnexportedStruct(t *testing.T) {
+	type Msg struct {
+		header
+		Val uint16
+	}
+	raw := []byte{0x01, 0x00, 0x02, 0xaa, 0xbb}
+
+	var m Msg
+	opts := &endian.Options{Strict: true}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.BigEndian, &m, opts); err != nil {
+		t.Fatalf("endian.ReadWithOptions err=%s", err)
+	}
+	if m.Type != 0x01 || m.Len != 0x0002 || m.Val != 0xaabb {
+		t.Errorf("mismatch given=%+v", m)
+	}
+
+	ret, err := endian.MarshalWithOptions(endian.BigEndian, m, opts)
+	if err != nil {
+		t.Fatalf("endian.MarshalWithOptions err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestEmbeddedPointer(t *testing.T) {
+	type Msg struct {
+		*Header
+		Val uint16
+	}
+	raw := []byte{0x01, 0x00, 0x02, 0xaa, 0xbb}
+
+	var m Msg
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &m); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if m.Header == nil {
+		t.Fatalf("Header is not allocated")
+	}
+	if m.Type != 0x01 || m.Len != 0x0002 || m.Val != 0xaabb {
+		t.Errorf("mismatch given=%+v", m)
+	}
+
+	// nil pointer is written as zero value
+	ret, err := endian.Marshal(endian.BigEndian, Msg{Val: 0xaabb})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	expect := []byte{0x00, 0x00, 0x00, 0xaa, 0xbb}
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
+
+type recursiveNode struct {
+	V uint16
+	*recursiveNode
+}
+
+type recursiveA struct {
+	V uint8
+	*recursiveB
+}
+
+type recursiveB struct {
+	W uint8
+	*recursiveA
+}
+
+func TestEmbeddedRecursive(t *testing.T) {
+	raw := []byte{0x01, 0x02, 0x03, 0x04}
+
+	for _, data := range []interface{}{&recursiveNode{}, &recursiveA{}, &struct{ N [2]recursiveB }{}} {
+		if err := endian.Unmarshal(raw, endian.LittleEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if err := endian.Read(bytes.NewReader(raw), endian.LittleEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if _, err := endian.Marshal(endian.LittleEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if _, err := endian.LayoutOf(data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if _, _, _, err := endian.OffsetOf(data, "V"); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+	}
+	if _, err := endian.Decode[recursiveNode](raw, endian.LittleEndian); err == nil {
+		t.Errorf("error is not returned")
+	}
+	var s []recursiveNode
+	if err := endian.DecodeSlice(raw, endian.LittleEndian, &s, 1); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	/* same struct embedded twice is not recursive */
+	type Twice struct {
+		Header
+		Next struct{ Header }
+	}
+	var tw Twice
+	if err := endian.Unmarshal([]byte{1, 2, 0, 3, 4, 0}, endian.LittleEndian, &tw); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if tw.Len != 2 || tw.Next.Type != 3 {
+		t.Errorf("mismatch given=%+v", tw)
+	}
+}
@@ -17,21 +17,32 @@
 package endian
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
 )
 
 // write writes v to b.
-func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
+func write(v reflect.Value, order ByteOrder, b []byte, index *int, opts *Options) error {
 	var err error
 
 	if !v.CanInterface() {
 		// skip unexported field
-		*index += sizeOfValue(v, false)
+		fill(b, index, sizeOfValue(v, true, opts), opts.SkipFill)
 		return errCannotInterface
 	}
+	if c := opts.lookupCodec(v.Type()); c != nil {
+		return writeCodec(v, order, c, b, index)
+	}
+	if v.Type() == timeType {
+		return writeTime(v, order, nil, b, index)
+	}
+	if isBigInt(v.Type()) {
+		return writeBigInt(v, order, nil, b, index)
+	}
 
 	d := v.Interface()
 
@@ -67,6 +78,12 @@ func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
 		b[*index+6] = bs[6]
 		b[*index+7] = bs[7]
 		*index += 8
+	case float32:
+		order.PutUint32(b[*index:*index+4], math.Float32bits(d.(float32)))
+		*index += 4
+	case float64:
+		order.PutUint64(b[*index:*index+8], math.Float64bits(d.(float64)))
+		*index += 8
 	default:
 		switch v.Kind() {
 		case reflect.Slice, reflect.Array:
@@ -74,7 +91,7 @@ func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
 				if v.Index(0).Kind() == reflect.Uint8 {
 					// byte slice / byte array
 					length := v.Len()
-					if order == BigEndian {
+					if opts.reverseBytes(order) {
 						for i := 0; i < length; i++ {
 							b[*index+length-1-i] = byte(v.Index(i).Uint())
 						}
@@ -84,9 +101,11 @@ func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
 						}
 					}
 					*index += length
+				} else if writeBulk(v, order, b, index, opts) {
+					/* primitive slice/array */
 				} else {
 					for i := 0; i < v.Len(); i++ {
-						err := write(v.Index(i), order, b, index)
+						err := write(v.Index(i), order, b, index, opts)
 						if err != nil && err != errCannotInterface {
 							return err
 						}
@@ -94,36 +113,9 @@ func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
 				}
 			}
 		case reflect.Struct:
-			for i := 0; i < v.Type().NumField(); i++ {
-				f := v.Type().Field(i)
-				cnf := parseStructTag(f.Tag)
-				if cnf != nil {
-					/* struct tag is defined */
-					if cnf.ignore {
-						continue
-					} else if cnf.skip {
-						/* only updates offset. not fill. */
-						*index += sizeOfValue(v.Field(i), true)
-						continue
-					} else if cnf.endian != Endian_Type_BLANK {
-						var err error
-						if cnf.endian == Endian_Type_BE {
-							err = write(v.Field(i), BigEndian, b, index)
-						} else {
-							err = write(v.Field(i), LittleEndian, b, index)
-						}
-						if err != nil && err != errCannotInterface {
-							return err
-						}
-						continue
-					}
-				}
-				err := write(v.Field(i), order, b, index)
-				if err != nil && err != errCannotInterface {
-					return err
-				}
-			}
-			return nil
+			return writeStruct(v, order, b, index, opts)
+		case reflect.String:
+			return writeString(v, order, nil, b, index, opts)
 		default:
 			return fmt.Errorf("Not Supported %s", v.Kind())
 		}
@@ -132,8 +124,103 @@ func write(v reflect.Value, order ByteOrder, b []byte, index *int) error {
 	return err
 }
 
+// writeStruct writes fields of a struct v to b.
+func writeStruct(v reflect.Value, order ByteOrder, b []byte, index *int, opts *Options) error {
+	for i := 0; i < v.Type().NumField(); i++ {
+		f := v.Type().Field(i)
+		cnf, err := parseStructTag(f.Tag)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+		}
+		if cnf != nil {
+			/* struct tag is defined */
+			if cnf.ignore {
+				continue
+			} else if cnf.skipped() {
+				/* only updates offset. fill by SkipFill. */
+				fill(b, index, sizeOfField(fieldValue(v, i), cnf, opts), opts.SkipFill)
+				continue
+			}
+		}
+		fv := v.Field(i)
+		if opts.Unexported && f.PkgPath != "" && f.Name != "_" {
+			fv = exportField(fv)
+		} else if opts.Strict && isUnexported(f) {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, ErrUnexportedField)
+		}
+		if cnf != nil && cnf.rest && i != v.NumField()-1 {
+			return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, errRestNotLast)
+		}
+		if isEmbeddedStruct(f) && opts.lookupCodec(f.Type) == nil {
+			/* flatten embedded struct. the struct tag of the embedding site is inherited. */
+			ev, err := embeddedStruct(fv, false)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+			}
+			err = writeStruct(ev, cnf.byteOrder(order), b, index, opts.withTag(cnf))
+			if err != nil && err != errCannotInterface {
+				return err
+			}
+			continue
+		}
+		err = writeField(fv, cnf.byteOrder(order), cnf, b, index, opts.withTag(cnf))
+		if err != nil && err != errCannotInterface {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeField writes a struct field v which has the struct tag cnf.
+func writeField(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int, opts *Options) error {
+	if !v.CanInterface() {
+		// skip unexported field
+		fill(b, index, sizeOfField(v, cnf, opts), opts.SkipFill)
+		return errCannotInterface
+	}
+	if c := opts.lookupCodec(v.Type()); c != nil {
+		/* registered codec takes precedence over struct tags */
+		return writeCodec(v, order, c, b, index)
+	}
+
+	switch {
+	case cnf != nil && cnf.rest:
+		return writeRest(v, b, index)
+	case cnf.hasScale():
+		return writeScaled(v, order, cnf, b, index)
+	case cnf.hasFloat():
+		return writeFloat(v, order, cnf, b, index)
+	case cnf.hasDigit():
+		return writeDigit(v, order, cnf, b, index)
+	case isBigInt(v.Type()):
+		return writeBigInt(v, order, cnf, b, index)
+	case cnf.hasAddr():
+		return writeAddr(v, cnf, b, index)
+	case v.Type() == timeType && cnf.hasTime():
+		return writeTime(v, order, cnf, b, index)
+	case v.Kind() == reflect.String:
+		return writeString(v, order, cnf, b, index, opts)
+	}
+	return write(v, order, b, index, opts)
+}
+
+// fill fills n bytes of b from index by c and updates index.
+func fill(b []byte, index *int, n int, c byte) {
+	for i := 0; i < n; i++ {
+		b[*index+i] = c
+	}
+	*index += n
+}
+
 // Write writes structured binary data from input into w.
 func Write(w io.Writer, order ByteOrder, input interface{}) error {
+	return WriteWithOptions(w, order, input, nil)
+}
+
+// WriteWithOptions is like Write but the behavior is customized by opts.
+// If opts is nil, it is same as Write.
+func WriteWithOptions(w io.Writer, order ByteOrder, input interface{}, opts *Options) error {
+	opts = optionsOrDefault(opts)
 	v := reflect.ValueOf(input)
 	var vv reflect.Value
 
@@ -146,9 +233,55 @@ func Write(w io.Writer, order ByteOrder, input interface{}) error {
 		return binary.Write(w, order, input)
 	}
 
-	barr := make([]byte, sizeOfValue(vv, true))
-	index := 0
-	err := write(vv, order, barr, &index)
+	barr, err := marshal(vv, order, opts)
+	if err != nil {
+		return err
+	}
 	_, err = w.Write(barr)
 	return err
 }
+
+// Marshal is like Write but returns the encoded bytes.
+func Marshal(order ByteOrder, input interface{}) ([]byte, error) {
+	return MarshalWithOptions(order, input, nil)
+}
+
+// MarshalWithOptions is like Marshal but the behavior is customized by opts.
+// If opts is nil, it is same as Marshal.
+func MarshalWithOptions(order ByteOrder, input interface{}, opts *Options) ([]byte, error) {
+	opts = optionsOrDefault(opts)
+	v := reflect.ValueOf(input)
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		return marshal(reflect.Indirect(v), order, opts)
+	case reflect.Array, reflect.Slice, reflect.Struct:
+		return marshal(v, order, opts)
+	default:
+		buf := bytes.NewBuffer([]byte{})
+		if err := binary.Write(buf, order, input); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// marshal returns the encoded bytes of v.
+func marshal(v reflect.Value, order ByteOrder, opts *Options) ([]byte, error) {
+	/* unexported fields and bulk conversion of arrays require an addressable value */
+	v = addressable(v)
+	if err := checkRest(v.Type(), true, opts); err != nil {
+		return nil, err
+	}
+	c := sizeOfValue(v, true, opts)
+	if err := opts.checkAlloc(c); err != nil {
+		return nil, err
+	}
+	barr := make([]byte, c)
+	index := 0
+	err := write(v, order, barr, &index, opts)
+	if err != nil && err != errCannotInterface {
+		return nil, err
+	}
+	return barr, nil
+}
@@ -208,6 +208,58 @@ func TestWriteStruct(t *testing.T) {
 	}
 }
 
+func TestWriteRawBytes(t *testing.T) {
+	type S struct {
+		MAC [6]byte `endian:"raw"`
+		Val [2]byte
+	}
+
+	s := S{MAC: [6]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}, Val: [2]byte{0x01, 0x02}}
+	buf := bytes.NewBuffer([]byte{})
+	if err := endian.Write(buf, endian.BigEndian, s); err != nil {
+		t.Errorf("endian.Write err=%s", err)
+	}
+	expect := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x02, 0x01}
+	if ret := buf.Bytes(); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	buf.Reset()
+	opts := &endian.Options{ByteArray: endian.ByteArrayRaw}
+	if err := endian.WriteWithOptions(buf, endian.BigEndian, s, opts); err != nil {
+		t.Errorf("endian.WriteWithOptions err=%s", err)
+	}
+	expect = []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x01, 0x02}
+	if ret := buf.Bytes(); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type S struct {
+		B   byte
+		U16 uint16
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, S{B: 0xaa, U16: 0xbbcc})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	expect := []byte{0xaa, 0xbb, 0xcc}
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	ret, err = endian.Marshal(endian.LittleEndian, uint32(0x11223344))
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	expect = []byte{0x44, 0x33, 0x22, 0x11}
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
+
 func BenchmarkWriteStruct(b *testing.B) {
 	type Sample struct {
 		Header   byte
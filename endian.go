@@ -18,9 +18,23 @@ package endian
 
 import (
 	"encoding/binary"
+	"unsafe"
 )
 
 type ByteOrder binary.ByteOrder
 
 var BigEndian = binary.BigEndian
 var LittleEndian = binary.LittleEndian
+
+// NativeEndian is the byte order of the host.
+// It is BigEndian or LittleEndian and detected at init.
+var NativeEndian ByteOrder
+
+func init() {
+	var i uint16 = 0x0102
+	if *(*byte)(unsafe.Pointer(&i)) == 0x01 {
+		NativeEndian = BigEndian
+	} else {
+		NativeEndian = LittleEndian
+	}
+}
@@ -0,0 +1,179 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// default size of a scaled integer
+const defaultScaledSize = 2
+
+// hasScale reports whether the tag has fixed or scale.
+func (c *tagConfig) hasScale() bool {
+	return c != nil && c.scaled
+}
+
+// parseFixed parses "Qm.n" or "UQm.n" and sets scale, size and signed.
+// The size is m+n bits.
+func (c *tagConfig) parseFixed(value string) error {
+	s := value
+	signed := true
+	if strings.HasPrefix(s, "UQ") {
+		signed = false
+		s = s[2:]
+	} else if strings.HasPrefix(s, "Q") {
+		s = s[1:]
+	} else {
+		return fmt.Errorf("invalid fixed %q", value)
+	}
+	mn := strings.SplitN(s, ".", 2)
+	if len(mn) != 2 {
+		return fmt.Errorf("invalid fixed %q", value)
+	}
+	m, err := strconv.Atoi(mn[0])
+	if err != nil || m < 0 {
+		return fmt.Errorf("invalid fixed %q", value)
+	}
+	n, err := strconv.Atoi(mn[1])
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid fixed %q", value)
+	}
+	switch m + n {
+	case 8, 16, 32, 64:
+	default:
+		return fmt.Errorf("invalid fixed %q: m+n must be 8, 16, 32 or 64", value)
+	}
+	c.scaled = true
+	c.scale = math.Ldexp(1, -n)
+	c.size = (m + n) / 8
+	c.signed = signed
+	return nil
+}
+
+// sizeOfScaled returns the size of a scaled integer.
+func sizeOfScaled(cnf *tagConfig) int {
+	if cnf.size > 0 {
+		return cnf.size
+	}
+	return defaultScaledSize
+}
+
+// readUint reads an unsigned integer of size bytes from b.
+func readUint(b []byte, order ByteOrder, size int) (uint64, error) {
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(order.Uint16(b)), nil
+	case 4:
+		return uint64(order.Uint32(b)), nil
+	case 8:
+		return order.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("invalid integer size %d", size)
+}
+
+// putUint writes an unsigned integer of size bytes to b.
+func putUint(b []byte, order ByteOrder, size int, v uint64) error {
+	switch size {
+	case 1:
+		b[0] = byte(v)
+	case 2:
+		order.PutUint16(b, uint16(v))
+	case 4:
+		order.PutUint32(b, uint32(v))
+	case 8:
+		order.PutUint64(b, v)
+	default:
+		return fmt.Errorf("invalid integer size %d", size)
+	}
+	return nil
+}
+
+// signExtend converts an unsigned integer of size bytes to a signed integer.
+func signExtend(u uint64, size int) int64 {
+	shift := uint(64 - size*8)
+	return int64(u<<shift) >> shift
+}
+
+func errScaledKind(v reflect.Value) error {
+	return fmt.Errorf("Not Supported %s with fixed/scale, it must be float", v.Type())
+}
+
+// readScaled reads an integer from b and fill a float v by raw*scale+offset.
+func readScaled(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int) error {
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		return errScaledKind(v)
+	}
+	size := sizeOfScaled(cnf)
+	u, err := readUint(b[*index:*index+size], order, size)
+	if err != nil {
+		return err
+	}
+	*index += size
+
+	var raw float64
+	if cnf.signed {
+		raw = float64(signExtend(u, size))
+	} else {
+		raw = float64(u)
+	}
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.SetFloat(raw*cnf.scale + cnf.offset)
+	return nil
+}
+
+// writeScaled writes a float v to b as an integer round((v-offset)/scale).
+func writeScaled(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int) error {
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		return errScaledKind(v)
+	}
+	size := sizeOfScaled(cnf)
+	f := v.Float()
+	raw := math.Round((f - cnf.offset) / cnf.scale)
+
+	/* limit is exclusive. 2^k-1 can not be represented by float64 if k > 53. */
+	var min, limit float64
+	if cnf.signed {
+		min = -math.Ldexp(1, size*8-1)
+		limit = math.Ldexp(1, size*8-1)
+	} else {
+		limit = math.Ldexp(1, size*8)
+	}
+	if math.IsNaN(raw) || raw < min || raw >= limit {
+		return fmt.Errorf("%v overflows %d byte integer", f, size)
+	}
+
+	var u uint64
+	if cnf.signed {
+		u = uint64(int64(raw))
+	} else {
+		u = uint64(raw)
+	}
+	if err := putUint(b[*index:*index+size], order, size, u); err != nil {
+		return err
+	}
+	*index += size
+	return nil
+}
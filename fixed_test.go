@@ -0,0 +1,143 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"math"
+	"testing"
+)
+
+func TestFixed(t *testing.T) {
+	type S struct {
+		Q16  float64 `endian:"fixed=Q16.16"`
+		Q15  float32 `endian:"fixed=Q1.15"`
+		UQ8  float64 `endian:"fixed=UQ4.4"`
+		Temp float64 `endian:"scale=0.01,offset=-40"`
+		Volt float64 `endian:"scale=0.5,size=1,signed"`
+	}
+	raw := []byte{
+		0xff, 0xfe, 0x80, 0x00, // -1.5
+		0x40, 0x00, // 0.5
+		0x38,       // 3.5
+		0x1b, 0x58, // 7000*0.01-40 = 30
+		0xfc, // -4*0.5 = -2
+	}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Q16 != -1.5 {
+		t.Errorf("Q16 mismatch given=%v expect=-1.5", s.Q16)
+	}
+	if s.Q15 != 0.5 {
+		t.Errorf("Q15 mismatch given=%v expect=0.5", s.Q15)
+	}
+	if s.UQ8 != 3.5 {
+		t.Errorf("UQ8 mismatch given=%v expect=3.5", s.UQ8)
+	}
+	if math.Abs(s.Temp-30) > 1e-9 {
+		t.Errorf("Temp mismatch given=%v expect=30", s.Temp)
+	}
+	if s.Volt != -2 {
+		t.Errorf("Volt mismatch given=%v expect=-2", s.Volt)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestFixedRounding(t *testing.T) {
+	type S struct {
+		V float64 `endian:"fixed=Q8.8,LE"`
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, S{V: 1.0 / 3})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	// 0.333.. * 256 = 85.33 -> 85
+	if expect := []byte{0x55, 0x00}; bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	for _, v := range []float64{128, -128.01, math.NaN(), math.Inf(1)} {
+		if _, err := endian.Marshal(endian.BigEndian, S{V: v}); err == nil {
+			t.Errorf("%v: error is not returned", v)
+		}
+	}
+}
+
+func TestFixedError(t *testing.T) {
+	type Int struct {
+		V int32 `endian:"fixed=Q16.16"`
+	}
+	if err := endian.Unmarshal(make([]byte, 4), endian.BigEndian, &Int{}); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	type Invalid struct {
+		V float64 `endian:"fixed=Q3.3"`
+	}
+	if err := endian.Unmarshal(make([]byte, 4), endian.BigEndian, &Invalid{}); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestFixedOverflow8Byte(t *testing.T) {
+	type Q struct {
+		V float64 `endian:"fixed=Q32.32"`
+	}
+	type UQ struct {
+		V float64 `endian:"fixed=UQ32.32"`
+	}
+	type Scaled struct {
+		V float64 `endian:"scale=1,size=8"`
+	}
+
+	for _, s := range []interface{}{&Q{V: 2147483648.0}, &Q{V: -2147483648.5}, &UQ{V: 4294967296.0}, &Scaled{V: 18446744073709551616.0}} {
+		if ret, err := endian.Marshal(endian.BigEndian, s); err == nil {
+			t.Errorf("%+v: error is not returned. given=%x", s, ret)
+		}
+	}
+
+	cases := []struct {
+		s      interface{}
+		expect []byte
+	}{
+		{&Q{V: -2147483648.0}, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{&Q{V: 2147483647.0}, []byte{0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0}},
+		{&UQ{V: 4294967295.0}, []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		ret, err := endian.Marshal(endian.BigEndian, c.s)
+		if err != nil {
+			t.Errorf("%+v: endian.Marshal err=%s", c.s, err)
+			continue
+		}
+		if bytes.Compare(ret, c.expect) != 0 {
+			t.Errorf("mismatch\n given=%x\n expect=%x", ret, c.expect)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"reflect"
+)
+
+const (
+	Float_Type_BLANK = iota
+	Float_Type_F16
+	Float_Type_BF16
+	Float_Type_F32
+	Float_Type_F64
+	Float_Type_F80
+)
+
+var floatTypes = map[string]int{
+	"f16":  Float_Type_F16,
+	"bf16": Float_Type_BF16,
+	"f32":  Float_Type_F32,
+	"f64":  Float_Type_F64,
+	"f80":  Float_Type_F80,
+}
+
+// hasFloat reports whether the tag has a float format.
+func (c *tagConfig) hasFloat() bool {
+	return c != nil && c.float != Float_Type_BLANK
+}
+
+// sizeOfFloat returns the size of a float field which has the struct tag cnf.
+func sizeOfFloat(cnf *tagConfig) int {
+	switch cnf.float {
+	case Float_Type_F16, Float_Type_BF16:
+		return 2
+	case Float_Type_F32:
+		return 4
+	case Float_Type_F64:
+		return 8
+	case Float_Type_F80:
+		return 10
+	}
+	return 0
+}
+
+// roundFloat rounds f to the nearest even value of a binary floating point format
+// which has ebits exponent and mbits mantissa and returns the bits.
+// f is rounded to infinity if it overflows.
+func roundFloat(f float64, ebits, mbits uint) uint64 {
+	b := math.Float64bits(f)
+	sign := (b >> 63) << (ebits + mbits)
+	exp := int((b >> 52) & 0x7ff)
+	mant := b & (1<<52 - 1)
+	bias := 1<<(ebits-1) - 1
+	inf := uint64(1<<ebits-1) << mbits
+
+	switch {
+	case exp == 0x7ff && mant != 0:
+		/* quiet NaN. keep upper bits of payload */
+		return sign | inf | 1<<(mbits-1) | mant>>(52-mbits)
+	case exp == 0x7ff:
+		return sign | inf
+	case exp == 0 && mant == 0:
+		return sign
+	}
+
+	/* f = sig * 2^(e-52) */
+	sig := mant
+	e := -1022
+	if exp != 0 {
+		sig |= 1 << 52
+		e = exp - 1023
+	}
+
+	te := e + bias
+	shift := 52 - int(mbits)
+	if te <= 0 {
+		/* subnormal */
+		shift += 1 - te
+		te = 1
+	}
+	if shift >= 64 {
+		return sign
+	}
+	m := sig >> uint(shift)
+	if shift > 0 {
+		rem := sig & (1<<uint(shift) - 1)
+		half := uint64(1) << uint(shift-1)
+		if rem > half || (rem == half && m&1 == 1) {
+			m++
+		}
+	}
+	/* m includes the implicit bit. a carry increments the exponent */
+	ret := uint64(te-1)<<mbits + m
+	if ret >= inf {
+		return sign | inf
+	}
+	return sign | ret
+}
+
+// expandFloat returns float64 of bits u which is a binary floating point format
+// which has ebits exponent and mbits mantissa.
+func expandFloat(u uint64, ebits, mbits uint) float64 {
+	sign := u >> (ebits + mbits) & 1
+	exp := int(u>>mbits) & (1<<ebits - 1)
+	mant := u & (1<<mbits - 1)
+	bias := 1<<(ebits-1) - 1
+
+	var f float64
+	switch exp {
+	case 1<<ebits - 1:
+		if mant != 0 {
+			return math.Float64frombits(sign<<63 | 0x7ff<<52 | 1<<51 | mant<<(52-mbits))
+		}
+		f = math.Inf(1)
+	case 0:
+		f = math.Ldexp(float64(mant), 1-bias-int(mbits))
+	default:
+		f = math.Ldexp(float64(mant|1<<mbits), exp-bias-int(mbits))
+	}
+	if sign != 0 {
+		f = -f
+	}
+	return f
+}
+
+// readF80 reads 80 bit extended precision float which is rounded to float64.
+// Sign and exponent are followed by significand in big endian, and vice versa.
+func readF80(b []byte, order ByteOrder) float64 {
+	var se uint16
+	var sig uint64
+	if order == BigEndian {
+		se = order.Uint16(b[0:2])
+		sig = order.Uint64(b[2:10])
+	} else {
+		sig = order.Uint64(b[0:8])
+		se = order.Uint16(b[8:10])
+	}
+	exp := int(se & 0x7fff)
+	neg := se&0x8000 != 0
+
+	var f float64
+	switch {
+	case exp == 0x7fff && sig<<1 != 0:
+		f = math.NaN()
+	case exp == 0x7fff:
+		f = math.Inf(1)
+	case sig == 0:
+		f = 0
+	default:
+		if exp == 0 {
+			/* denormal */
+			exp = 1
+		}
+		bf := new(big.Float).SetUint64(sig)
+		f, _ = bf.SetMantExp(bf, exp-16383-63).Float64()
+	}
+	if neg {
+		f = math.Copysign(f, -1)
+	}
+	return f
+}
+
+// putF80 writes f as 80 bit extended precision float. It is exact.
+func putF80(b []byte, order ByteOrder, f float64) {
+	u := math.Float64bits(f)
+	se := uint16(u>>63) << 15
+	exp := int((u >> 52) & 0x7ff)
+	mant := u & (1<<52 - 1)
+
+	var sig uint64
+	switch {
+	case exp == 0x7ff && mant != 0:
+		se |= 0x7fff
+		sig = 0xc000000000000000 | mant<<11
+	case exp == 0x7ff:
+		se |= 0x7fff
+		sig = 1 << 63
+	case exp == 0 && mant == 0:
+	case exp == 0:
+		/* float64 subnormal is normal in 80 bit */
+		n := bits.Len64(mant)
+		sig = mant << uint(64-n)
+		se |= uint16(n - 1 - 1074 + 16383)
+	default:
+		sig = (mant | 1<<52) << 11
+		se |= uint16(exp - 1023 + 16383)
+	}
+
+	if order == BigEndian {
+		order.PutUint16(b[0:2], se)
+		order.PutUint64(b[2:10], sig)
+	} else {
+		order.PutUint64(b[0:8], sig)
+		order.PutUint16(b[8:10], se)
+	}
+}
+
+func errFloatKind(v reflect.Value) error {
+	return fmt.Errorf("Not Supported %s with float tag, it must be float", v.Type())
+}
+
+// readFloat reads a float in the format of the tag and fill a float v.
+func readFloat(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int) error {
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		return errFloatKind(v)
+	}
+	size := sizeOfFloat(cnf)
+	bs := b[*index : *index+size]
+
+	var f float64
+	switch cnf.float {
+	case Float_Type_F16:
+		f = expandFloat(uint64(order.Uint16(bs)), 5, 10)
+	case Float_Type_BF16:
+		f = expandFloat(uint64(order.Uint16(bs)), 8, 7)
+	case Float_Type_F32:
+		f = float64(math.Float32frombits(order.Uint32(bs)))
+	case Float_Type_F64:
+		f = math.Float64frombits(order.Uint64(bs))
+	case Float_Type_F80:
+		f = readF80(bs, order)
+	}
+	*index += size
+
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.SetFloat(f)
+	return nil
+}
+
+// writeFloat writes a float v in the format of the tag.
+func writeFloat(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int) error {
+	if v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+		return errFloatKind(v)
+	}
+	size := sizeOfFloat(cnf)
+	bs := b[*index : *index+size]
+	f := v.Float()
+
+	switch cnf.float {
+	case Float_Type_F16:
+		order.PutUint16(bs, uint16(roundFloat(f, 5, 10)))
+	case Float_Type_BF16:
+		order.PutUint16(bs, uint16(roundFloat(f, 8, 7)))
+	case Float_Type_F32:
+		order.PutUint32(bs, math.Float32bits(float32(f)))
+	case Float_Type_F64:
+		order.PutUint64(bs, math.Float64bits(f))
+	case Float_Type_F80:
+		putF80(bs, order, f)
+	}
+	*index += size
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"math"
+	"testing"
+)
+
+func TestFloat(t *testing.T) {
+	type S struct {
+		F32 float32
+		F64 float64
+	}
+	raw := []byte{0x3f, 0x80, 0x00, 0x00, 0x40, 0x09, 0x21, 0xfb, 0x54, 0x44, 0x2d, 0x18}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.F32 != 1 || s.F64 != math.Pi {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+type f16 struct {
+	V float64 `endian:"float=f16"`
+}
+
+type bf16 struct {
+	V float32 `endian:"float=bf16"`
+}
+
+type f80 struct {
+	V float64 `endian:"float=f80"`
+}
+
+func TestFloatHalf(t *testing.T) {
+	cases := []struct {
+		v      float64
+		raw    []byte
+		decode float64
+	}{
+		{1, []byte{0x3c, 0x00}, 1},
+		{-2, []byte{0xc0, 0x00}, -2},
+		{65504, []byte{0x7b, 0xff}, 65504},
+		{65520, []byte{0x7c, 0x00}, math.Inf(1)},
+		{math.Inf(-1), []byte{0xfc, 0x00}, math.Inf(-1)},
+		{math.Ldexp(1, -24), []byte{0x00, 0x01}, math.Ldexp(1, -24)},
+		{math.Ldexp(1, -26), []byte{0x00, 0x00}, 0},
+		{0.1, []byte{0x2e, 0x66}, 0.0999755859375},
+		// round to nearest even
+		{1 + math.Ldexp(1, -11), []byte{0x3c, 0x00}, 1},
+		{1 + 3*math.Ldexp(1, -11), []byte{0x3c, 0x02}, 1 + math.Ldexp(1, -9)},
+		// subnormal rounds up to normal
+		{math.Ldexp(1023.5, -24), []byte{0x04, 0x00}, math.Ldexp(1, -14)},
+	}
+
+	for _, c := range cases {
+		ret, err := endian.Marshal(endian.BigEndian, f16{V: c.v})
+		if err != nil {
+			t.Fatalf("%v: endian.Marshal err=%s", c.v, err)
+		}
+		if bytes.Compare(ret, c.raw) != 0 {
+			t.Errorf("%v: mismatch given=%x expect=%x", c.v, ret, c.raw)
+		}
+
+		var s f16
+		if err := endian.Unmarshal(c.raw, endian.BigEndian, &s); err != nil {
+			t.Fatalf("%v: endian.Unmarshal err=%s", c.v, err)
+		}
+		if s.V != c.decode {
+			t.Errorf("%x: mismatch given=%v expect=%v", c.raw, s.V, c.decode)
+		}
+	}
+
+	// NaN
+	ret, err := endian.Marshal(endian.LittleEndian, f16{V: math.NaN()})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	var s f16
+	if err := endian.Unmarshal(ret, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if ret[1]&0x7c != 0x7c || !math.IsNaN(s.V) {
+		t.Errorf("NaN mismatch given=%x %v", ret, s.V)
+	}
+}
+
+func TestFloatBfloat16(t *testing.T) {
+	ret, err := endian.Marshal(endian.LittleEndian, bf16{V: math.Pi})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if expect := []byte{0x49, 0x40}; bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch given=%x expect=%x", ret, expect)
+	}
+
+	var s bf16
+	if err := endian.Unmarshal(ret, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.V != 3.140625 {
+		t.Errorf("mismatch given=%v expect=3.140625", s.V)
+	}
+}
+
+func TestFloatExtended(t *testing.T) {
+	// AIFF sample rate 44100Hz
+	aiff := []byte{0x40, 0x0e, 0xac, 0x44, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	var s f80
+	if err := endian.Unmarshal(aiff, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.V != 44100 {
+		t.Errorf("mismatch given=%v expect=44100", s.V)
+	}
+
+	for _, order := range []endian.ByteOrder{endian.BigEndian, endian.LittleEndian} {
+		for _, v := range []float64{44100, -1.5, math.Pi, math.SmallestNonzeroFloat64, math.MaxFloat64, math.Inf(1), 0} {
+			ret, err := endian.Marshal(order, f80{V: v})
+			if err != nil {
+				t.Fatalf("%v: endian.Marshal err=%s", v, err)
+			}
+			if err := endian.Unmarshal(ret, order, &s); err != nil {
+				t.Fatalf("%v: endian.Unmarshal err=%s", v, err)
+			}
+			if s.V != v {
+				t.Errorf("%v: round trip mismatch given=%v raw=%x", v, s.V, ret)
+			}
+		}
+	}
+	ret, err := endian.Marshal(endian.BigEndian, f80{V: 44100})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, aiff) != 0 {
+		t.Errorf("mismatch given=%x expect=%x", ret, aiff)
+	}
+
+	// 1+2^-53 rounds to 1 and 1+2^-53+2^-63 rounds up
+	tie := []byte{0x3f, 0xff, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x00}
+	if err := endian.Unmarshal(tie, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.V != 1 {
+		t.Errorf("mismatch given=%v expect=1", s.V)
+	}
+	tie[9] = 0x01
+	if err := endian.Unmarshal(tie, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if expect := 1 + math.Ldexp(1, -52); s.V != expect {
+		t.Errorf("mismatch given=%v expect=%v", s.V, expect)
+	}
+
+	// NaN
+	nan := []byte{0x7f, 0xff, 0xc0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if err := endian.Unmarshal(nan, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if !math.IsNaN(s.V) {
+		t.Errorf("mismatch given=%v expect=NaN", s.V)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Decode decodes b as T.
+func Decode[T any](b []byte, order ByteOrder) (T, error) {
+	return DecodeWithOptions[T](b, order, nil)
+}
+
+// DecodeWithOptions is like Decode but the behavior is customized by opts.
+// If opts is nil, it is same as Decode.
+// A fixed-size T is decoded by the plan which is cached per T.
+func DecodeWithOptions[T any](b []byte, order ByteOrder, opts *Options) (T, error) {
+	var ret T
+	opts = optionsOrDefault(opts)
+	p, err := planOf(reflect.TypeOf((*T)(nil)).Elem(), opts)
+	if err != nil {
+		return ret, err
+	}
+	if p.variable || (p.fields == nil && p.elem == nil) {
+		err := UnmarshalWithOptions(b, order, &ret, opts)
+		return ret, err
+	}
+	if len(b) < p.size {
+		return ret, fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", p.size, len(b))
+	}
+	index := 0
+	err = p.decode(b, order, reflect.ValueOf(&ret).Elem(), &index, opts)
+	if err != nil && err != errCannotInterface {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// Encode returns the encoded bytes of v.
+func Encode[T any](order ByteOrder, v T) ([]byte, error) {
+	return EncodeWithOptions(order, v, nil)
+}
+
+// EncodeWithOptions is like Encode but the behavior is customized by opts.
+// If opts is nil, it is same as Encode.
+func EncodeWithOptions[T any](order ByteOrder, v T, opts *Options) ([]byte, error) {
+	return MarshalWithOptions(order, &v, opts)
+}
+
+// RecordReader reads consecutive fixed-size records of T from io.Reader.
+//
+//	rs := endian.Records[Entry](r, endian.LittleEndian)
+//	for rs.Next() {
+//		e := rs.Record()
+//	}
+//	if err := rs.Err(); err != nil {
+//	}
+type RecordReader[T any] struct {
+	r     io.Reader
+	order ByteOrder
+	opts  *Options
+	plan  *plan
+	buf   []byte
+	rec   T
+	n     int
+	err   error
+}
+
+// Records returns a RecordReader of T which reads from r.
+// The struct tags of T are parsed once and the plan is cached per T.
+func Records[T any](r io.Reader, order ByteOrder) *RecordReader[T] {
+	return RecordsWithOptions[T](r, order, nil)
+}
+
+// RecordsWithOptions is like Records but the behavior is customized by opts.
+// If opts is nil, it is same as Records.
+func RecordsWithOptions[T any](r io.Reader, order ByteOrder, opts *Options) *RecordReader[T] {
+	opts = optionsOrDefault(opts)
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	rs := &RecordReader[T]{r: r, order: order, opts: opts}
+	p, err := planOf(t, opts)
+	rs.plan = p
+	switch {
+	case err != nil:
+		rs.err = fmt.Errorf("endian.Records: %w", err)
+	case rs.plan.variable:
+		rs.err = fmt.Errorf("endian.Records: %s: %w", t, ErrVariableOffset)
+	case rs.plan.size == 0:
+		rs.err = fmt.Errorf("endian.Records: size of %s is 0", t)
+	default:
+		rs.buf = make([]byte, rs.plan.size)
+	}
+	return rs
+}
+
+// Next reads the next record. It returns false at the end of input or if an error occurs.
+func (rs *RecordReader[T]) Next() bool {
+	if rs.err != nil {
+		return false
+	}
+	if _, err := io.ReadFull(rs.r, rs.buf); err != nil {
+		if !errors.Is(err, io.EOF) {
+			rs.err = &RecordError{Index: rs.n, Err: err}
+		}
+		return false
+	}
+	var rec T
+	index := 0
+	err := rs.plan.decode(rs.buf, rs.order, reflect.ValueOf(&rec).Elem(), &index, rs.opts)
+	if err != nil && err != errCannotInterface {
+		rs.err = &RecordError{Index: rs.n, Err: err}
+		return false
+	}
+	rs.rec = rec
+	rs.n++
+	return true
+}
+
+// Record returns the record which is read by Next.
+func (rs *RecordReader[T]) Record() T {
+	return rs.rec
+}
+
+// Err returns the first error except io.EOF.
+func (rs *RecordReader[T]) Err() error {
+	return rs.err
+}
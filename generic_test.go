@@ -0,0 +1,177 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nokute78/go-endian"
+	"io"
+	"testing"
+)
+
+func TestDecodeEncode(t *testing.T) {
+	type S struct {
+		A uint16
+		B uint32 `endian:"LE"`
+	}
+	raw := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+
+	s, err := endian.Decode[S](raw, endian.BigEndian)
+	if err != nil {
+		t.Fatalf("endian.Decode err=%s", err)
+	}
+	if s.A != 0x0102 || s.B != 0x06050403 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Encode(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Encode err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	u, err := endian.Decode[uint32](raw, endian.LittleEndian)
+	if err != nil || u != 0x04030201 {
+		t.Errorf("mismatch given=%#x err=%v", u, err)
+	}
+	if ret, err := endian.Encode(endian.LittleEndian, u); err != nil || bytes.Compare(ret, raw[:4]) != 0 {
+		t.Errorf("mismatch given=%x err=%v", ret, err)
+	}
+
+	if _, err := endian.Decode[S](raw[:5], endian.BigEndian); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestRecords(t *testing.T) {
+	type R struct {
+		ID   uint16
+		Code uint8 `endian:"bcd"`
+	}
+	raw := []byte{0x00, 0x01, 0x12, 0x00, 0x02, 0x34, 0x00, 0x03, 0x56}
+
+	rs := endian.Records[R](bytes.NewReader(raw), endian.BigEndian)
+	var given []R
+	for rs.Next() {
+		given = append(given, rs.Record())
+	}
+	if err := rs.Err(); err != nil {
+		t.Fatalf("Err err=%s", err)
+	}
+	expect := []R{{1, 12}, {2, 34}, {3, 56}}
+	if len(given) != len(expect) {
+		t.Fatalf("mismatch given=%+v expect=%+v", given, expect)
+	}
+	for i := range expect {
+		if given[i] != expect[i] {
+			t.Errorf("%d: mismatch given=%+v expect=%+v", i, given[i], expect[i])
+		}
+	}
+
+	/* short record */
+	rs = endian.Records[R](bytes.NewReader(raw[:7]), endian.BigEndian)
+	n := 0
+	for rs.Next() {
+		n++
+	}
+	var rerr *endian.RecordError
+	if !errors.As(rs.Err(), &rerr) || rerr.Index != 2 || !errors.Is(rs.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("RecordError is not returned. n=%d err=%v", n, rs.Err())
+	}
+
+	/* invalid digit */
+	bad := append([]byte{}, raw...)
+	bad[5] = 0x3a
+	rs = endian.Records[R](bytes.NewReader(bad), endian.BigEndian)
+	for rs.Next() {
+	}
+	if !errors.As(rs.Err(), &rerr) || rerr.Index != 1 {
+		t.Errorf("RecordError is not returned. err=%v", rs.Err())
+	}
+
+	vs := endian.Records[struct{ B []byte }](bytes.NewReader(raw), endian.BigEndian)
+	if vs.Next() || !errors.Is(vs.Err(), endian.ErrVariableOffset) {
+		t.Errorf("ErrVariableOffset is not returned. err=%v", vs.Err())
+	}
+}
+
+func TestRecordsPlan(t *testing.T) {
+	type Point struct {
+		X uint16 `endian:"LE"`
+		Y uint16
+	}
+	type Head struct {
+		Type uint8
+		_    [1]byte
+	}
+	type R struct {
+		Head
+		Points [2]Point
+		Pad    uint8   `endian:"-"`
+		Skip   [2]byte `endian:"skip"`
+		Code   uint8   `endian:"bcd"`
+		Inner  struct {
+			A uint8
+			b uint8
+		} `endian:"LE"`
+		tail uint16
+	}
+	raw := []byte{0x01, 0xff, 0x02, 0x00, 0x00, 0x03, 0x04, 0x00, 0x00, 0x05, 0xaa, 0xbb, 0x42, 0x06, 0x07, 0x08, 0x09}
+	raw = append(raw, raw...)
+	raw[17] = 0x0a
+
+	for _, opts := range []*endian.Options{nil, {Unexported: true}} {
+		var expect [2]R
+		if err := endian.UnmarshalWithOptions(raw, endian.BigEndian, &expect, opts); err != nil {
+			t.Fatalf("endian.Unmarshal err=%s", err)
+		}
+		d, err := endian.DecodeWithOptions[R](raw, endian.BigEndian, opts)
+		if err != nil {
+			t.Fatalf("endian.Decode err=%s", err)
+		}
+		if d != expect[0] {
+			t.Errorf("mismatch given=%+v expect=%+v", d, expect[0])
+		}
+
+		rs := endian.RecordsWithOptions[R](bytes.NewReader(raw), endian.BigEndian, opts)
+		i := 0
+		for ; rs.Next(); i++ {
+			if given := rs.Record(); given != expect[i] {
+				t.Errorf("%d: mismatch given=%+v expect=%+v", i, given, expect[i])
+			}
+		}
+		if err := rs.Err(); err != nil {
+			t.Fatalf("Err err=%s", err)
+		}
+		if i != len(expect) {
+			t.Errorf("mismatch given=%d expect=%d", i, len(expect))
+		}
+	}
+
+	if _, err := endian.DecodeWithOptions[R](raw, endian.BigEndian, &endian.Options{Strict: true}); !errors.Is(err, endian.ErrUnexportedField) {
+		t.Errorf("ErrUnexportedField is not returned. err=%v", err)
+	}
+	if _, err := endian.Decode[R](raw[:16], endian.BigEndian); err == nil {
+		t.Errorf("error is not returned")
+	}
+	rs := endian.RecordsWithOptions[R](bytes.NewReader(raw), endian.BigEndian, &endian.Options{Strict: true})
+	if rs.Next() || !errors.Is(rs.Err(), endian.ErrUnexportedField) {
+		t.Errorf("ErrUnexportedField is not returned. err=%v", rs.Err())
+	}
+}
@@ -0,0 +1,132 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GUID represents a Microsoft GUID.
+// It is encoded in mixed endian form regardless of the byte order.
+// Data1, Data2 and Data3 are little endian and Data4 is copied verbatim.
+type GUID struct {
+	Data1 uint32
+	Data2 uint16
+	Data3 uint16
+	Data4 [8]byte
+}
+
+const guidSize = 16
+
+func init() {
+	RegisterCodec(reflect.TypeOf(GUID{}), guidCodec{})
+}
+
+// guidCodec is the Codec of GUID.
+type guidCodec struct{}
+
+func (guidCodec) Size() int {
+	return guidSize
+}
+
+func (guidCodec) Decode(b []byte, order ByteOrder) (interface{}, error) {
+	g := GUID{
+		Data1: binary.LittleEndian.Uint32(b[0:4]),
+		Data2: binary.LittleEndian.Uint16(b[4:6]),
+		Data3: binary.LittleEndian.Uint16(b[6:8]),
+	}
+	copy(g.Data4[:], b[8:16])
+	return g, nil
+}
+
+func (guidCodec) Encode(b []byte, order ByteOrder, v interface{}) error {
+	g := v.(GUID)
+	binary.LittleEndian.PutUint32(b[0:4], g.Data1)
+	binary.LittleEndian.PutUint16(b[4:6], g.Data2)
+	binary.LittleEndian.PutUint16(b[6:8], g.Data3)
+	copy(b[8:16], g.Data4[:])
+	return nil
+}
+
+// GUIDFromUUID converts a RFC 4122 UUID which is big endian into GUID.
+func GUIDFromUUID(u [16]byte) GUID {
+	g := GUID{
+		Data1: binary.BigEndian.Uint32(u[0:4]),
+		Data2: binary.BigEndian.Uint16(u[4:6]),
+		Data3: binary.BigEndian.Uint16(u[6:8]),
+	}
+	copy(g.Data4[:], u[8:16])
+	return g
+}
+
+// UUID returns g as a RFC 4122 UUID which is big endian.
+func (g GUID) UUID() [16]byte {
+	var u [16]byte
+	binary.BigEndian.PutUint32(u[0:4], g.Data1)
+	binary.BigEndian.PutUint16(u[4:6], g.Data2)
+	binary.BigEndian.PutUint16(u[6:8], g.Data3)
+	copy(u[8:16], g.Data4[:])
+	return u
+}
+
+// EqualUUID reports whether g and a RFC 4122 UUID u represent the same value.
+func (g GUID) EqualUUID(u [16]byte) bool {
+	return g.UUID() == u
+}
+
+// String returns g as "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX".
+func (g GUID) String() string {
+	return fmt.Sprintf("%08X-%04X-%04X-%04X-%012X", g.Data1, g.Data2, g.Data3, g.Data4[0:2], g.Data4[2:8])
+}
+
+// ParseGUID parses s as "XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX".
+// Braces are optional and it is case insensitive.
+func ParseGUID(s string) (GUID, error) {
+	str := s
+	if strings.HasPrefix(str, "{") && strings.HasSuffix(str, "}") {
+		str = str[1 : len(str)-1]
+	}
+	if len(str) != 36 || str[8] != '-' || str[13] != '-' || str[18] != '-' || str[23] != '-' {
+		return GUID{}, fmt.Errorf("invalid GUID %q", s)
+	}
+	u, err := hex.DecodeString(str[0:8] + str[9:13] + str[14:18] + str[19:23] + str[24:36])
+	if err != nil {
+		return GUID{}, fmt.Errorf("invalid GUID %q: %w", s, err)
+	}
+	var b [16]byte
+	copy(b[:], u)
+	return GUIDFromUUID(b), nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (g GUID) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (g *GUID) UnmarshalText(text []byte) error {
+	ret, err := ParseGUID(string(text))
+	if err != nil {
+		return err
+	}
+	*g = ret
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+// EFI System Partition GUID
+// C12A7328-F81F-11D2-BA4B-00A0C93EC93B
+var espGUIDRaw = []byte{0x28, 0x73, 0x2a, 0xc1, 0x1f, 0xf8, 0xd2, 0x11, 0xba, 0x4b, 0x00, 0xa0, 0xc9, 0x3e, 0xc9, 0x3b}
+
+const espGUIDStr = "C12A7328-F81F-11D2-BA4B-00A0C93EC93B"
+
+func TestGUID(t *testing.T) {
+	type Entry struct {
+		Type  endian.GUID
+		Attrs uint16
+	}
+	raw := append(append([]byte{}, espGUIDRaw...), 0x01, 0x02)
+
+	// byte order doesn't affect GUID
+	for _, order := range []endian.ByteOrder{endian.LittleEndian, endian.BigEndian} {
+		var e Entry
+		if err := endian.Unmarshal(raw, order, &e); err != nil {
+			t.Fatalf("endian.Unmarshal err=%s", err)
+		}
+		if s := e.Type.String(); s != espGUIDStr {
+			t.Errorf("mismatch given=%s expect=%s", s, espGUIDStr)
+		}
+
+		ret, err := endian.Marshal(order, e)
+		if err != nil {
+			t.Fatalf("endian.Marshal err=%s", err)
+		}
+		if bytes.Compare(ret, raw) != 0 {
+			t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+		}
+	}
+}
+
+func TestParseGUID(t *testing.T) {
+	for _, s := range []string{espGUIDStr, "{c12a7328-f81f-11d2-ba4b-00a0c93ec93b}"} {
+		g, err := endian.ParseGUID(s)
+		if err != nil {
+			t.Fatalf("%s: endian.ParseGUID err=%s", s, err)
+		}
+		if g.String() != espGUIDStr {
+			t.Errorf("mismatch given=%s expect=%s", g, espGUIDStr)
+		}
+	}
+
+	for _, s := range []string{"", "C12A7328F81F11D2BA4B00A0C93EC93B", "X12A7328-F81F-11D2-BA4B-00A0C93EC93B"} {
+		if _, err := endian.ParseGUID(s); err == nil {
+			t.Errorf("%q: error is not returned", s)
+		}
+	}
+
+	var g endian.GUID
+	if err := g.UnmarshalText([]byte(espGUIDStr)); err != nil {
+		t.Fatalf("UnmarshalText err=%s", err)
+	}
+	text, err := g.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText err=%s", err)
+	}
+	if string(text) != espGUIDStr {
+		t.Errorf("mismatch given=%s expect=%s", text, espGUIDStr)
+	}
+}
+
+func TestGUIDUUID(t *testing.T) {
+	uuid := [16]byte{0xc1, 0x2a, 0x73, 0x28, 0xf8, 0x1f, 0x11, 0xd2, 0xba, 0x4b, 0x00, 0xa0, 0xc9, 0x3e, 0xc9, 0x3b}
+
+	g, err := endian.ParseGUID(espGUIDStr)
+	if err != nil {
+		t.Fatalf("endian.ParseGUID err=%s", err)
+	}
+	if !g.EqualUUID(uuid) {
+		t.Errorf("EqualUUID returns false. uuid=%x", g.UUID())
+	}
+	if endian.GUIDFromUUID(uuid) != g {
+		t.Errorf("mismatch given=%s expect=%s", endian.GUIDFromUUID(uuid), g)
+	}
+}
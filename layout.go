@@ -0,0 +1,241 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Layout represents where a value lands in the encoded bytes.
+type Layout struct {
+	Name     string       // field name. "[i]" for an element of array or slice.
+	Type     reflect.Type // Go type of the field
+	Offset   int          // offset in bytes from the beginning of the root value
+	Size     int          // size in bytes
+	Order    ByteOrder    // byte order of the field. nil if it follows the order of the caller.
+	Tag      string       // value of the struct tag
+	Skip     bool         // the field has skip tag
+	Keep     bool         // the field has skip,keep tag
+	Ignore   bool         // the field has "-" tag
+	Embedded bool         // the field is an embedded struct which is flattened
+	Fields   []*Layout    // fields of a struct or elements of an array
+
+	value reflect.Value
+	cnf   *tagConfig
+	opts  *Options // options to encode the field
+}
+
+// LayoutOf returns the layout of v.
+// v is a value or a pointer to the value which is passed to Write.
+// The size of each field is computed by the same logic as Read and Write.
+func LayoutOf(v interface{}) (*Layout, error) {
+	return LayoutOfWithOptions(v, nil)
+}
+
+// LayoutOfWithOptions is like LayoutOf but the layout is computed with opts.
+// If opts is nil, it is same as LayoutOf.
+func LayoutOfWithOptions(v interface{}, opts *Options) (*Layout, error) {
+	opts = optionsOrDefault(opts)
+	l, err := rootLayout(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := layoutOfValue(l, l.value, nil, opts); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// rootLayout returns the layout of v without children.
+func rootLayout(v interface{}, opts *Options) (*Layout, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("endian.LayoutOf: nil pointer %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("endian.LayoutOf: invalid value")
+	}
+	if err := checkRest(rv.Type(), true, opts); err != nil {
+		return nil, err
+	}
+	return &Layout{Type: rv.Type(), Size: sizeOfValue(rv, true, opts), value: rv, opts: opts}, nil
+}
+
+// layoutOfValue fills children of l whose value is v.
+func layoutOfValue(l *Layout, v reflect.Value, order ByteOrder, opts *Options) error {
+	if opts.lookupCodec(v.Type()) != nil || v.Type() == timeType || isBigInt(v.Type()) {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		fields, err := layoutOfStruct(v, l.Offset, order, opts)
+		if err != nil {
+			return err
+		}
+		l.Fields = fields
+	case reflect.Array, reflect.Slice:
+		if v.Len() == 0 || v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		offset := l.Offset
+		for i := 0; i < v.Len(); i++ {
+			e := &Layout{
+				Name:   fmt.Sprintf("[%d]", i),
+				Type:   v.Type().Elem(),
+				Offset: offset,
+				Size:   sizeOfValue(v.Index(i), true, opts),
+				Order:  order,
+				value:  v.Index(i),
+				opts:   opts,
+			}
+			if err := layoutOfValue(e, v.Index(i), order, opts); err != nil {
+				return err
+			}
+			l.Fields = append(l.Fields, e)
+			offset += e.Size
+		}
+	}
+	return nil
+}
+
+// layoutOfStruct returns the layouts of fields of a struct v which begins at offset.
+func layoutOfStruct(v reflect.Value, offset int, order ByteOrder, opts *Options) ([]*Layout, error) {
+	var ret []*Layout
+	for i := 0; i < v.Type().NumField(); i++ {
+		l, err := fieldLayout(v, i, offset, order, opts)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, l)
+		if l.Ignore {
+			continue
+		}
+		offset += l.Size
+		if l.leaf() {
+			continue
+		}
+		if err := layoutOfValue(l, l.value, l.Order, l.opts); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// fieldLayout returns the layout of i-th field of a struct v without children.
+// The field begins at offset.
+func fieldLayout(v reflect.Value, i int, offset int, order ByteOrder, opts *Options) (*Layout, error) {
+	f := v.Type().Field(i)
+	cnf, err := parseStructTag(f.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: %w", v.Type(), f.Name, err)
+	}
+	fv := fieldValue(v, i)
+	if opts.Unexported && f.PkgPath != "" && f.Name != "_" {
+		fv = exportField(fv)
+	}
+	l := &Layout{
+		Name:   f.Name,
+		Type:   f.Type,
+		Offset: offset,
+		Order:  cnf.byteOrder(order),
+		Tag:    f.Tag.Get(tagKeyName),
+		value:  fv,
+		cnf:    cnf,
+		opts:   opts.withTag(cnf),
+	}
+	if cnf != nil {
+		l.Ignore = cnf.ignore
+		l.Skip = cnf.skip
+		l.Keep = cnf.skip && cnf.keep
+	}
+	if l.Ignore {
+		return l, nil
+	}
+	l.Size = sizeOfField(fv, cnf, opts)
+	if !l.leaf() && isEmbeddedStruct(f) && opts.lookupCodec(f.Type) == nil {
+		l.Embedded = true
+	}
+	return l, nil
+}
+
+// leaf reports whether the field l has no children since the tag encodes the whole field.
+func (l *Layout) leaf() bool {
+	return l.Ignore || l.cnf.skipped() || l.cnf.encoded()
+}
+
+// encoded reports whether the tag changes the wire encoding of the field.
+// Such a field is a leaf of the layout.
+func (c *tagConfig) encoded() bool {
+	if c == nil {
+		return false
+	}
+	return c.rest || c.hasScale() || c.hasFloat() || c.hasDigit() || c.hasAddr() || c.hasTime()
+}
+
+// String returns the layout as an indented table.
+func (l *Layout) String() string {
+	var sb strings.Builder
+	l.print(&sb, 0)
+	return sb.String()
+}
+
+func (l *Layout) print(w io.Writer, depth int) {
+	name := l.Name
+	if name != "" {
+		name += " "
+	}
+	flags := []string{}
+	if l.Order != nil {
+		flags = append(flags, orderName(l.Order))
+	}
+	switch {
+	case l.Ignore:
+		flags = append(flags, "ignore")
+	case l.Keep:
+		flags = append(flags, "keep")
+	case l.Skip:
+		flags = append(flags, "skip")
+	}
+	if l.Embedded {
+		flags = append(flags, "embedded")
+	}
+	fmt.Fprintf(w, "0x%04x %6d %s%s%s", l.Offset, l.Size, strings.Repeat("  ", depth), name, l.Type)
+	if len(flags) > 0 {
+		fmt.Fprintf(w, " [%s]", strings.Join(flags, ","))
+	}
+	fmt.Fprintln(w)
+	for _, f := range l.Fields {
+		f.print(w, depth+1)
+	}
+}
+
+// orderName returns the name of order which is used by struct tags.
+func orderName(order ByteOrder) string {
+	switch order {
+	case BigEndian:
+		return "BE"
+	case LittleEndian:
+		return "LE"
+	}
+	return order.String()
+}
@@ -0,0 +1,107 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"github.com/nokute78/go-endian"
+	"strings"
+	"testing"
+)
+
+func TestLayoutOf(t *testing.T) {
+	type Header struct {
+		Magic uint32 `endian:"BE"`
+		Ver   uint16
+	}
+	type Entry struct {
+		Flags uint16
+		Pad   [2]byte `endian:"skip"`
+	}
+	type S struct {
+		Header
+		Name    [8]byte `endian:"raw"`
+		Entries [3]Entry
+		Ignored uint64 `endian:"-"`
+		Size    uint32 `endian:"LE"`
+	}
+
+	l, err := endian.LayoutOf(&S{})
+	if err != nil {
+		t.Fatalf("endian.LayoutOf err=%s", err)
+	}
+	if l.Size != 30 || len(l.Fields) != 5 {
+		t.Fatalf("mismatch given size=%d fields=%d expect size=30 fields=5", l.Size, len(l.Fields))
+	}
+
+	type expect struct {
+		name   string
+		offset int
+		size   int
+		order  endian.ByteOrder
+	}
+	cases := []struct {
+		l      *endian.Layout
+		expect expect
+	}{
+		{l.Fields[0], expect{"Header", 0, 6, nil}},
+		{l.Fields[0].Fields[0], expect{"Magic", 0, 4, endian.BigEndian}},
+		{l.Fields[0].Fields[1], expect{"Ver", 4, 2, nil}},
+		{l.Fields[1], expect{"Name", 6, 8, nil}},
+		{l.Fields[2], expect{"Entries", 14, 12, nil}},
+		{l.Fields[2].Fields[2], expect{"[2]", 22, 4, nil}},
+		{l.Fields[2].Fields[2].Fields[1], expect{"Pad", 24, 2, nil}},
+		{l.Fields[3], expect{"Ignored", 26, 0, nil}},
+		{l.Fields[4], expect{"Size", 26, 4, endian.LittleEndian}},
+	}
+	for i, c := range cases {
+		given := expect{c.l.Name, c.l.Offset, c.l.Size, c.l.Order}
+		if given != c.expect {
+			t.Errorf("%d: mismatch given=%+v expect=%+v", i, given, c.expect)
+		}
+	}
+
+	if !l.Fields[0].Embedded {
+		t.Errorf("Header is not embedded")
+	}
+	if !l.Fields[2].Fields[2].Fields[1].Skip {
+		t.Errorf("Pad is not skip")
+	}
+	if !l.Fields[3].Ignore {
+		t.Errorf("Ignored is not ignore")
+	}
+	if l.Fields[1].Tag != "raw" || len(l.Fields[1].Fields) != 0 {
+		t.Errorf("Name mismatch given=%+v", l.Fields[1])
+	}
+
+	s := l.String()
+	for _, line := range []string{
+		"0x0000     30 endian_test.S\n",
+		"0x0000      4     Magic uint32 [BE]",
+		"0x0018      2       Pad [2]uint8 [skip]",
+	} {
+		if !strings.Contains(s, line) {
+			t.Errorf("%q is not found in\n%s", line, s)
+		}
+	}
+}
+
+func TestLayoutOfNil(t *testing.T) {
+	var p *struct{ A uint8 }
+	if _, err := endian.LayoutOf(p); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// UnmarshalFields is like Unmarshal but decodes only the fields of data pointed by paths.
+// Other fields are not changed. A path is same as OffsetOf.
+// Slices are decoded by their current length and a rest field consumes all remaining bytes of b.
+// A length prefixed string without size tag is decoded by the prefix.
+func UnmarshalFields(b []byte, order ByteOrder, data interface{}, paths ...string) error {
+	return UnmarshalFieldsWithOptions(b, order, data, paths, nil)
+}
+
+// UnmarshalFieldsWithOptions is like UnmarshalFields but the behavior is customized by opts.
+// If opts is nil, it is same as UnmarshalFields.
+func UnmarshalFieldsWithOptions(b []byte, order ByteOrder, data interface{}, paths []string, opts *Options) error {
+	ls, err := layoutsOf(data, paths, opts)
+	if err != nil {
+		return err
+	}
+	for i, l := range ls {
+		end := l.Offset + l.Size
+		if l.unbounded() {
+			end = len(b)
+		}
+		if end > len(b) {
+			return fmt.Errorf("endian.Read:short read %s, expect=%d byte, read=%d byte", paths[i], end, len(b))
+		}
+		if err := decodeLayout(b[l.Offset:end], order, l, l.value); err != nil {
+			return fmt.Errorf("%s: %w", paths[i], err)
+		}
+	}
+	return nil
+}
+
+// ReadFieldsAt is like UnmarshalFields but reads only the bytes of the fields from r at base plus the offset.
+// rest field and length prefixed string without size tag are not supported since the size is unknown.
+func ReadFieldsAt(r io.ReaderAt, base int64, order ByteOrder, data interface{}, paths ...string) error {
+	return ReadFieldsAtWithOptions(r, base, order, data, paths, nil)
+}
+
+// ReadFieldsAtWithOptions is like ReadFieldsAt but the behavior is customized by opts.
+// If opts is nil, it is same as ReadFieldsAt.
+func ReadFieldsAtWithOptions(r io.ReaderAt, base int64, order ByteOrder, data interface{}, paths []string, opts *Options) error {
+	ls, err := layoutsOf(data, paths, opts)
+	if err != nil {
+		return err
+	}
+	for i, l := range ls {
+		if l.unbounded() {
+			return fmt.Errorf("%s: variable size field is not supported by ReadFieldsAt", paths[i])
+		}
+		b := make([]byte, l.Size)
+		n, err := r.ReadAt(b, base+int64(l.Offset))
+		if n != len(b) {
+			if err == nil || err == io.EOF {
+				err = fmt.Errorf("endian.Read:short read %s, expect=%d byte, read=%d byte", paths[i], len(b), n)
+			}
+			return err
+		}
+		if err := decodeLayout(b, order, l, l.value); err != nil {
+			return fmt.Errorf("%s: %w", paths[i], err)
+		}
+	}
+	return nil
+}
+
+// layoutsOf returns the layouts of the fields of data pointed by paths.
+// Only the fields along paths are visited.
+func layoutsOf(data interface{}, paths []string, opts *Options) ([]*Layout, error) {
+	if v := reflect.ValueOf(data); v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("endian: %T is not a pointer", data)
+	}
+	opts = optionsOrDefault(opts)
+	root, err := rootLayout(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Layout, len(paths))
+	for i, path := range paths {
+		ret[i], err = root.lookup(path, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// decodeLayout decodes b as the field l into v.
+func decodeLayout(b []byte, order ByteOrder, l *Layout, v reflect.Value) error {
+	if l.Order != nil {
+		order = l.Order
+	}
+	if !v.CanInterface() && l.opts.Strict {
+		return ErrUnexportedField
+	}
+	index := 0
+	err := readField(b, order, v, l.cnf, &index, l.opts)
+	if err != nil && err != errCannotInterface && err != errStringResized {
+		return err
+	}
+	if index > len(b) {
+		return fmt.Errorf("endian.Read:short read, expect=%d byte, read=%d byte", index, len(b))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type lazyRecord struct {
+	ID      uint32 `endian:"BE"`
+	Values  [4]uint16
+	Name    string `endian:"size=4"`
+	Flags   uint8
+	Payload []byte `endian:"rest"`
+}
+
+var lazyRaw = []byte{
+	0x00, 0x00, 0x01, 0x02,
+	0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00,
+	'a', 'b', 'c', 0,
+	0x80,
+	0xde, 0xad,
+}
+
+// countingReaderAt counts bytes read by ReadAt.
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += n
+	return n, err
+}
+
+func TestUnmarshalFields(t *testing.T) {
+	r := lazyRecord{Flags: 0xff}
+	err := endian.UnmarshalFields(lazyRaw, endian.LittleEndian, &r, "ID", "Values[2]", "Name", "Payload")
+	if err != nil {
+		t.Fatalf("endian.UnmarshalFields err=%s", err)
+	}
+	if r.ID != 0x102 || r.Values != [4]uint16{0, 0, 3, 0} || r.Name != "abc" || r.Flags != 0xff {
+		t.Errorf("mismatch given=%+v", r)
+	}
+	if bytes.Compare(r.Payload, []byte{0xde, 0xad}) != 0 {
+		t.Errorf("mismatch given=%x", r.Payload)
+	}
+
+	if err := endian.UnmarshalFields(lazyRaw[:10], endian.LittleEndian, &r, "Name"); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.UnmarshalFields(lazyRaw, endian.LittleEndian, r, "ID"); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestReadFieldsAt(t *testing.T) {
+	base := []byte{0xaa, 0xbb}
+	c := &countingReaderAt{r: bytes.NewReader(append(base, lazyRaw...))}
+
+	var r lazyRecord
+	if err := endian.ReadFieldsAt(c, int64(len(base)), endian.LittleEndian, &r, "Flags", "Values[1]"); err != nil {
+		t.Fatalf("endian.ReadFieldsAt err=%s", err)
+	}
+	if r.Flags != 0x80 || r.Values[1] != 2 || r.ID != 0 || r.Name != "" {
+		t.Errorf("mismatch given=%+v", r)
+	}
+	if c.n != 3 {
+		t.Errorf("read size mismatch given=%d expect=3", c.n)
+	}
+
+	if err := endian.ReadFieldsAt(c, int64(len(base)), endian.LittleEndian, &r, "Payload"); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.ReadFieldsAt(c, int64(len(base)+10), endian.LittleEndian, &r, "Flags"); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestUnmarshalFieldsWithOptions(t *testing.T) {
+	type S struct {
+		ID     uint16
+		Hash   [4]byte
+		secret uint16
+	}
+	raw := []byte{0x00, 0x01, 0x01, 0x02, 0x03, 0x04, 0x12, 0x34}
+
+	var s S
+	if err := endian.UnmarshalFields(raw, endian.BigEndian, &s, "Hash", "secret"); err != nil {
+		t.Fatalf("endian.UnmarshalFields err=%s", err)
+	}
+	if s.Hash != [4]byte{0x04, 0x03, 0x02, 0x01} || s.secret != 0 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	s = S{}
+	opts := &endian.Options{ByteArray: endian.ByteArrayRaw, Unexported: true}
+	if err := endian.UnmarshalFieldsWithOptions(raw, endian.BigEndian, &s, []string{"Hash", "secret"}, opts); err != nil {
+		t.Fatalf("endian.UnmarshalFieldsWithOptions err=%s", err)
+	}
+	if s.Hash != [4]byte{0x01, 0x02, 0x03, 0x04} || s.secret != 0x1234 || s.ID != 0 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	s = S{}
+	if err := endian.ReadFieldsAtWithOptions(bytes.NewReader(raw), 0, endian.BigEndian, &s, []string{"secret"}, opts); err != nil {
+		t.Fatalf("endian.ReadFieldsAtWithOptions err=%s", err)
+	}
+	if s.secret != 0x1234 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	strict := &endian.Options{Strict: true}
+	if err := endian.UnmarshalFieldsWithOptions(raw, endian.BigEndian, &s, []string{"secret"}, strict); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestUnmarshalFieldsWide(t *testing.T) {
+	type Entry struct {
+		Flags uint16
+		Count uint32
+	}
+	type Wide struct {
+		Magic   uint32
+		Entries [1 << 20]Entry
+	}
+	w := new(Wide)
+	raw := make([]byte, 4+6*3)
+	raw[0] = 0x01
+	raw[4+6*2] = 0x02
+
+	if err := endian.UnmarshalFields(raw, endian.LittleEndian, w, "Magic", "Entries[2].Flags"); err != nil {
+		t.Fatalf("endian.UnmarshalFields err=%s", err)
+	}
+	if w.Magic != 1 || w.Entries[2].Flags != 2 {
+		t.Errorf("mismatch given=%d,%d expect=1,2", w.Magic, w.Entries[2].Flags)
+	}
+
+	/* the rest of the record is not materialized */
+	allocs := testing.AllocsPerRun(10, func() {
+		endian.UnmarshalFields(raw, endian.LittleEndian, w, "Entries[2].Flags")
+	})
+	if allocs > 100 {
+		t.Errorf("too many allocations given=%v", allocs)
+	}
+}
@@ -0,0 +1,268 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrVariableOffset is returned if the offset of a field depends on the value of preceding fields.
+// e.g. a slice, rest field or length prefixed string without size tag precedes the field.
+var ErrVariableOffset = errors.New("offset depends on the value")
+
+// OffsetOf returns the offset, the size and the byte order of a field of v.
+// path is a field path like "Header.Entries[2].Flags". Fields of embedded structs can be promoted.
+// Byte arrays and slices are leaves, so their elements can not be pointed.
+// order is nil if the field follows the byte order of the caller.
+// ErrVariableOffset is returned if the offset depends on the value, since it may not match other data.
+func OffsetOf(v interface{}, path string) (offset, size int, order ByteOrder, err error) {
+	l, err := lookupLayout(v, path, nil)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return l.Offset, l.Size, l.Order, nil
+}
+
+// lookupLayout returns the layout of the field of v which is pointed by path.
+func lookupLayout(v interface{}, path string, opts *Options) (*Layout, error) {
+	opts = optionsOrDefault(opts)
+	l, err := rootLayout(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	return l.lookup(path, opts)
+}
+
+// lookup returns the layout of the field which is pointed by path from l.
+// Only the fields along path are visited, so the cost does not depend on the number of elements.
+// The returned layout has no children.
+func (l *Layout) lookup(path string, opts *Options) (*Layout, error) {
+	names, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		var next *Layout
+		if name[0] == '[' {
+			next, err = l.element(name, path, opts)
+		} else {
+			next, err = l.field(name, path, opts)
+		}
+		if err != nil {
+			return nil, err
+		}
+		l = next
+	}
+	return l, nil
+}
+
+// field returns the layout of the field name of a struct l.
+// Fields of embedded structs are promoted.
+func (l *Layout) field(name string, path string, opts *Options) (*Layout, error) {
+	var chain []int
+	if l.hasChildren(reflect.Struct, opts) {
+		chain = findField(l.value.Type(), name, opts, map[reflect.Type]bool{})
+	}
+	if chain == nil {
+		return nil, fmt.Errorf("endian: field %q is not found in %s", name, l.Type)
+	}
+	for _, i := range chain {
+		offset := l.Offset
+		for j := 0; j < i; j++ {
+			f, err := fieldLayout(l.value, j, offset, l.Order, l.opts)
+			if err != nil {
+				return nil, err
+			}
+			if f.Ignore {
+				continue
+			}
+			if isVariableLayout(f, opts) {
+				return nil, fmt.Errorf("endian: %s.%s precedes %q: %w", l.Type, f.Name, path, ErrVariableOffset)
+			}
+			offset += f.Size
+		}
+		f, err := fieldLayout(l.value, i, offset, l.Order, l.opts)
+		if err != nil {
+			return nil, err
+		}
+		l = f
+	}
+	if l.Ignore {
+		return nil, fmt.Errorf("endian: field %q is ignored", path)
+	}
+	return l, nil
+}
+
+// element returns the layout of the element name like "[2]" of an array or a slice l.
+func (l *Layout) element(name string, path string, opts *Options) (*Layout, error) {
+	i, _ := strconv.Atoi(name[1 : len(name)-1])
+	v := l.value
+	if !l.hasChildren(reflect.Array, opts) && !l.hasChildren(reflect.Slice, opts) ||
+		v.Type().Elem().Kind() == reflect.Uint8 || i >= v.Len() {
+		return nil, fmt.Errorf("endian: field %q is not found in %s", name, l.Type)
+	}
+	if i > 0 && isVariableType(v.Type().Elem(), opts) {
+		return nil, fmt.Errorf("endian: %s.[0] precedes %q: %w", l.Type, path, ErrVariableOffset)
+	}
+	size := sizeOfValue(v.Index(i), true, l.opts)
+	return &Layout{
+		Name:   name,
+		Type:   v.Type().Elem(),
+		Offset: l.Offset + i*size,
+		Size:   size,
+		Order:  l.Order,
+		value:  v.Index(i),
+		opts:   l.opts,
+	}, nil
+}
+
+// hasChildren reports whether l is a value of kind which has fields or elements in the layout.
+func (l *Layout) hasChildren(kind reflect.Kind, opts *Options) bool {
+	if l.leaf() || !l.value.IsValid() || l.value.Kind() != kind {
+		return false
+	}
+	t := l.value.Type()
+	return opts.lookupCodec(t) == nil && t != timeType && !isBigInt(t)
+}
+
+// findField returns the indexes of fields from a struct t to the field name.
+// Fields of embedded structs are also searched.
+func findField(t reflect.Type, name string, opts *Options, seen map[reflect.Type]bool) []int {
+	if f, ok := t.FieldByName(name); ok && len(f.Index) == 1 {
+		return f.Index
+	}
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		cnf, _ := parseStructTag(f.Tag)
+		if cnf != nil && (cnf.ignore || cnf.skipped() || cnf.encoded()) {
+			continue
+		}
+		if !isEmbeddedStruct(f) || opts.lookupCodec(f.Type) != nil {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if chain := findField(ft, name, opts, seen); chain != nil {
+			return append([]int{i}, chain...)
+		}
+	}
+	return nil
+}
+
+// parsePath splits path into field names and element indexes like "[2]".
+func parsePath(path string) ([]string, error) {
+	var ret []string
+	for _, s := range strings.Split(path, ".") {
+		name := s
+		var idx []string
+		if i := strings.IndexByte(s, '['); i >= 0 {
+			name = s[:i]
+			for rest := s[i:]; rest != ""; {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("endian: invalid path %q", path)
+				}
+				n, err := strconv.Atoi(rest[1:end])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("endian: invalid index in path %q", path)
+				}
+				idx = append(idx, fmt.Sprintf("[%d]", n))
+				rest = rest[end+1:]
+			}
+		}
+		if name == "" && (len(ret) > 0 || len(idx) == 0) {
+			return nil, fmt.Errorf("endian: invalid path %q", path)
+		}
+		if name != "" {
+			ret = append(ret, name)
+		}
+		ret = append(ret, idx...)
+	}
+	return ret, nil
+}
+
+// isVariableLayout reports whether the size of l depends on the value.
+func isVariableLayout(l *Layout, opts *Options) bool {
+	if l.Ignore || opts.lookupCodec(l.Type) != nil {
+		return false
+	}
+	if l.unbounded() {
+		return true
+	}
+	if l.cnf.encoded() {
+		return false
+	}
+	t := l.Type
+	if l.Embedded && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return isVariableType(t, opts)
+}
+
+// isVariableType reports whether the size of a value of t depends on the value.
+func isVariableType(t reflect.Type, opts *Options) bool {
+	if opts.lookupCodec(t) != nil || t == timeType || isBigInt(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && isVariableType(t.Elem(), opts)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			cnf, _ := parseStructTag(f.Tag)
+			if cnf != nil && cnf.ignore {
+				continue
+			}
+			if cnf != nil && (cnf.rest || (cnf.isVarString() && f.Type.Kind() == reflect.String)) {
+				return true
+			}
+			if cnf.encoded() {
+				continue
+			}
+			ft := f.Type
+			if isEmbeddedStruct(f) && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if isVariableType(ft, opts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unbounded reports whether the size of the field l is determined by the encoded bytes.
+// rest field and length prefixed string without size tag may extend to the end of the buffer.
+func (l *Layout) unbounded() bool {
+	if l.cnf == nil {
+		return false
+	}
+	return l.cnf.rest || (l.cnf.isVarString() && l.Type.Kind() == reflect.String)
+}
@@ -0,0 +1,121 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"errors"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+func TestOffsetOf(t *testing.T) {
+	type Entry struct {
+		Type  uint8
+		Flags uint16 `endian:"BE"`
+	}
+	type Header struct {
+		Magic   uint32
+		Entries [4]Entry
+	}
+	type Common struct {
+		Ver uint16
+	}
+	type S struct {
+		Common
+		Header  Header
+		Matrix  [2][3]uint16
+		Payload []byte
+		Tail    uint32
+		Data    []byte `endian:"rest"`
+	}
+
+	type expect struct {
+		offset int
+		size   int
+		order  endian.ByteOrder
+	}
+	cases := []struct {
+		name   string
+		path   string
+		expect expect
+	}{
+		{"field", "Header.Magic", expect{2, 4, nil}},
+		{"element", "Header.Entries[2]", expect{12, 3, nil}},
+		{"element field", "Header.Entries[2].Flags", expect{13, 2, endian.BigEndian}},
+		{"2d array", "Matrix[1][2]", expect{28, 2, nil}},
+		{"promoted", "Ver", expect{0, 2, nil}},
+		{"embedded", "Common.Ver", expect{0, 2, nil}},
+		{"slice", "Payload", expect{30, 3, nil}},
+	}
+
+	s := &S{Payload: make([]byte, 3)}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			offset, size, order, err := endian.OffsetOf(s, c.path)
+			if err != nil {
+				t.Fatalf("endian.OffsetOf err=%s", err)
+			}
+			given := expect{offset, size, order}
+			if given != c.expect {
+				t.Errorf("mismatch given=%+v expect=%+v", given, c.expect)
+			}
+		})
+	}
+
+	for _, path := range []string{"Tail", "Data"} {
+		if _, _, _, err := endian.OffsetOf(s, path); !errors.Is(err, endian.ErrVariableOffset) {
+			t.Errorf("%s: ErrVariableOffset is not returned. err=%v", path, err)
+		}
+	}
+	for _, path := range []string{"", "Unknown", "Header.Entries[4]", "Header.Entries[x]", "Header..Magic", "Header.Entries[1", "Payload[1]"} {
+		if _, _, _, err := endian.OffsetOf(s, path); err == nil {
+			t.Errorf("%q: error is not returned", path)
+		}
+	}
+}
+
+func TestOffsetOfLarge(t *testing.T) {
+	type Entry struct {
+		Type  uint8
+		Flags uint16
+	}
+	type Image struct {
+		Magic   uint32
+		Entries [1 << 20]Entry
+		Tail    uint32
+	}
+	s := new(Image)
+
+	offset, size, _, err := endian.OffsetOf(s, "Entries[2].Flags")
+	if err != nil {
+		t.Fatalf("endian.OffsetOf err=%s", err)
+	}
+	if offset != 11 || size != 2 {
+		t.Errorf("mismatch given=%d,%d expect=11,2", offset, size)
+	}
+	if offset, _, _, _ = endian.OffsetOf(s, "Tail"); offset != 4+3<<20 {
+		t.Errorf("mismatch given=%d expect=%d", offset, 4+3<<20)
+	}
+
+	/* only the fields along the path are visited */
+	allocs := testing.AllocsPerRun(10, func() {
+		endian.OffsetOf(s, "Entries[1000].Flags")
+	})
+	if allocs > 100 {
+		t.Errorf("too many allocations given=%v", allocs)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ByteArrayMode represents how byte arrays and byte slices are treated.
+type ByteArrayMode int
+
+const (
+	// ByteArrayReverse reverses byte arrays if the order is BigEndian.
+	// It is useful for n-byte integers. It is the default.
+	ByteArrayReverse ByteArrayMode = iota
+	// ByteArrayRaw copies byte arrays verbatim regardless of the order.
+	// It is useful for MAC addresses, hashes and names.
+	ByteArrayRaw
+)
+
+// StringMode represents how string fields without size tag are treated.
+type StringMode int
+
+const (
+	// StringError returns an error. It is the default.
+	StringError StringMode = iota
+	// StringIgnore ignores the field like `endian:"-"`.
+	StringIgnore
+)
+
+// Options represents options of ReadWithOptions and WriteWithOptions.
+// The zero value is the default behavior of Read and Write.
+type Options struct {
+	// Strict returns ErrUnexportedField if a struct has an unexported field.
+	// Unexported fields are skipped if Strict is false.
+	// Blank(_) fields are always skipped.
+	Strict bool
+
+	// Unexported reads and writes unexported fields through unsafe.
+	// Strict is not applied to unexported fields if Unexported is true.
+	Unexported bool
+
+	// String is the treatment of string fields without `endian:"size=N"`.
+	String StringMode
+
+	// SkipFill is written to `endian:"skip"` and skipped fields by Write.
+	SkipFill byte
+
+	// MaxAlloc is the maximum size in bytes to be allocated by a call.
+	// 0 means no limit.
+	MaxAlloc int
+
+	// ByteArray is the default treatment of byte arrays and byte slices.
+	// `endian:"raw"` and `endian:"reverse"` override it.
+	ByteArray ByteArrayMode
+
+	// Codecs overrides Codecs which are registered by RegisterCodec.
+	// A nil Codec disables the registered Codec.
+	Codecs map[reflect.Type]Codec
+}
+
+var defaultOptions = Options{}
+
+func optionsOrDefault(opts *Options) *Options {
+	if opts == nil {
+		return &defaultOptions
+	}
+	return opts
+}
+
+// checkAlloc returns an error if size exceeds MaxAlloc.
+func (o *Options) checkAlloc(size int) error {
+	if o.MaxAlloc > 0 && size > o.MaxAlloc {
+		return fmt.Errorf("size %d exceeds MaxAlloc %d", size, o.MaxAlloc)
+	}
+	return nil
+}
+
+// reverseBytes reports whether byte arrays should be reversed in the order.
+func (o *Options) reverseBytes(order ByteOrder) bool {
+	return o.ByteArray == ByteArrayReverse && order == BigEndian
+}
+
+// withTag returns options which are overridden by the struct tag.
+func (o *Options) withTag(c *tagConfig) *Options {
+	if c == nil || (c.bytes == Bytes_Type_BLANK && !c.keep) {
+		return o
+	}
+	ret := *o
+	if c.keep {
+		/* original bytes are kept as is */
+		ret.ByteArray = ByteArrayRaw
+		return &ret
+	}
+	switch c.bytes {
+	case Bytes_Type_RAW:
+		ret.ByteArray = ByteArrayRaw
+	case Bytes_Type_REVERSE:
+		ret.ByteArray = ByteArrayReverse
+	}
+	return &ret
+}
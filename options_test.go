@@ -0,0 +1,154 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+func TestOptionsStrict(t *testing.T) {
+	type S struct {
+		A byte
+		b byte
+		_ byte
+		C byte
+	}
+
+	var s S
+	raw := []byte{0xaa, 0xbb, 0xcc, 0xdd}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.LittleEndian, &s, nil); err != nil {
+		t.Errorf("endian.ReadWithOptions err=%s", err)
+	}
+	if s.A != 0xaa || s.b != 0 || s.C != 0xdd {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	opts := &endian.Options{Strict: true}
+	err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.LittleEndian, &s, opts)
+	if !errors.Is(err, endian.ErrUnexportedField) {
+		t.Errorf("ErrUnexportedField is not returned. err=%v", err)
+	}
+	err = endian.WriteWithOptions(bytes.NewBuffer([]byte{}), endian.LittleEndian, s, opts)
+	if !errors.Is(err, endian.ErrUnexportedField) {
+		t.Errorf("ErrUnexportedField is not returned. err=%v", err)
+	}
+
+	type Blank struct {
+		A byte
+		_ byte
+		C byte
+	}
+	var b Blank
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.LittleEndian, &b, opts); err != nil {
+		t.Errorf("blank field: err=%s", err)
+	}
+	if b.A != 0xaa || b.C != 0xcc {
+		t.Errorf("mismatch given=%+v", b)
+	}
+}
+
+func TestOptionsString(t *testing.T) {
+	type S struct {
+		A    byte
+		Name string
+	}
+
+	var s S
+	raw := []byte{0xaa, 0xbb}
+	if err := endian.Read(bytes.NewBuffer(raw), endian.LittleEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	opts := &endian.Options{String: endian.StringIgnore}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.LittleEndian, &s, opts); err != nil {
+		t.Fatalf("endian.ReadWithOptions err=%s", err)
+	}
+	if s.A != 0xaa || s.Name != "" {
+		t.Errorf("mismatch given=%+v", s)
+	}
+}
+
+func TestOptionsSkipFill(t *testing.T) {
+	type S struct {
+		A        byte
+		Reserved [3]byte `endian:"skip"`
+		B        byte
+	}
+
+	s := S{A: 0x01, Reserved: [3]byte{0xaa, 0xbb, 0xcc}, B: 0x02}
+	buf := bytes.NewBuffer([]byte{})
+	opts := &endian.Options{SkipFill: 0xff}
+	if err := endian.WriteWithOptions(buf, endian.LittleEndian, s, opts); err != nil {
+		t.Fatalf("endian.WriteWithOptions err=%s", err)
+	}
+	expect := []byte{0x01, 0xff, 0xff, 0xff, 0x02}
+	if ret := buf.Bytes(); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
+
+func TestOptionsMaxAlloc(t *testing.T) {
+	var b [16]byte
+	opts := &endian.Options{MaxAlloc: 8}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(make([]byte, 16)), endian.LittleEndian, &b, opts); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.WriteWithOptions(bytes.NewBuffer([]byte{}), endian.LittleEndian, b, opts); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	opts.MaxAlloc = 16
+	if err := endian.ReadWithOptions(bytes.NewBuffer(make([]byte, 16)), endian.LittleEndian, &b, opts); err != nil {
+		t.Errorf("endian.ReadWithOptions err=%s", err)
+	}
+}
+
+func TestOptionsUnexported(t *testing.T) {
+	type inner struct {
+		x uint16
+	}
+	type S struct {
+		A byte
+		b uint16
+		_ byte
+		c [2]byte
+		d inner
+	}
+
+	raw := []byte{0xaa, 0x01, 0x02, 0xff, 0x03, 0x04, 0x05, 0x06}
+	opts := &endian.Options{Unexported: true, Strict: true}
+
+	var s S
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.LittleEndian, &s, opts); err != nil {
+		t.Fatalf("endian.ReadWithOptions err=%s", err)
+	}
+	if s.A != 0xaa || s.b != 0x0201 || s.c != [2]byte{0x03, 0x04} || s.d.x != 0x0605 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+
+	ret, err := endian.MarshalWithOptions(endian.LittleEndian, s, opts)
+	if err != nil {
+		t.Fatalf("endian.MarshalWithOptions err=%s", err)
+	}
+	expect := []byte{0xaa, 0x01, 0x02, 0x00, 0x03, 0x04, 0x05, 0x06}
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// RecordError is returned by DecodeSlice if a record fails to decode.
+type RecordError struct {
+	Index int // index of the record
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %s", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeSlice decodes b as consecutive fixed-size records into dst which is a pointer to a slice.
+// The records are decoded in parallel by workers goroutines and their order is preserved.
+// If workers <= 0, runtime.GOMAXPROCS(0) is used.
+// The struct tags are parsed once and the cached plan is shared by the workers.
+// If some records fail, *RecordError of the first record is returned and dst is not changed.
+func DecodeSlice(b []byte, order ByteOrder, dst interface{}, workers int) error {
+	return DecodeSliceWithOptions(b, order, dst, workers, nil)
+}
+
+// DecodeSliceWithOptions is like DecodeSlice but the behavior is customized by opts.
+// If opts is nil, it is same as DecodeSlice.
+func DecodeSliceWithOptions(b []byte, order ByteOrder, dst interface{}, workers int, opts *Options) error {
+	opts = optionsOrDefault(opts)
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("endian.DecodeSlice: %T is not a pointer to slice", dst)
+	}
+	t := v.Elem().Type().Elem()
+	p, err := planOf(t, opts)
+	if err != nil {
+		return fmt.Errorf("endian.DecodeSlice: %w", err)
+	}
+	if p.variable {
+		return fmt.Errorf("endian.DecodeSlice: %s: %w", t, ErrVariableOffset)
+	}
+	if p.size == 0 {
+		return fmt.Errorf("endian.DecodeSlice: size of %s is 0", t)
+	}
+	if len(b)%p.size != 0 {
+		return fmt.Errorf("endian.DecodeSlice: %d byte is not a multiple of record size %d byte", len(b), p.size)
+	}
+	n := len(b) / p.size
+	if err := opts.checkAlloc(n * int(t.Size())); err != nil {
+		return err
+	}
+	s := reflect.MakeSlice(v.Elem().Type(), n, n)
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunk := n / (workers * 4)
+	if chunk < 1 {
+		chunk = 1
+	}
+
+	var (
+		mu    sync.Mutex
+		first *RecordError
+		wg    sync.WaitGroup
+	)
+	/* records after the first error don't need to be decoded */
+	failed := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return first != nil && first.Index < i
+	}
+	chunks := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				if failed(start) {
+					continue
+				}
+				end := start + chunk
+				if end > n {
+					end = n
+				}
+				for i := start; i < end; i++ {
+					index := i * p.size
+					err := p.decode(b, order, s.Index(i), &index, opts)
+					if err != nil && err != errCannotInterface {
+						mu.Lock()
+						if first == nil || i < first.Index {
+							first = &RecordError{Index: i, Err: err}
+						}
+						mu.Unlock()
+						break
+					}
+				}
+			}
+		}()
+	}
+	for start := 0; start < n; start += chunk {
+		chunks <- start
+	}
+	close(chunks)
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	v.Elem().Set(s)
+	return nil
+}
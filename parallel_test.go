@@ -0,0 +1,138 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"errors"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type parallelRecord struct {
+	ID    uint32 `endian:"BE"`
+	Value uint16
+	Code  uint8 `endian:"ascii=dec,size=2"`
+}
+
+func parallelRaw(n int) []byte {
+	raw := make([]byte, 0, n*8)
+	for i := 0; i < n; i++ {
+		raw = append(raw, 0, 0, byte(i>>8), byte(i), byte(i), byte(i>>8), '0'+byte(i%100/10), '0'+byte(i%10))
+	}
+	return raw
+}
+
+func TestDecodeSlice(t *testing.T) {
+	n := 1000
+	raw := parallelRaw(n)
+	for _, workers := range []int{0, 1, 3, 64} {
+		var rs []parallelRecord
+		if err := endian.DecodeSlice(raw, endian.LittleEndian, &rs, workers); err != nil {
+			t.Fatalf("workers=%d: endian.DecodeSlice err=%s", workers, err)
+		}
+		if len(rs) != n {
+			t.Fatalf("workers=%d: length mismatch given=%d expect=%d", workers, len(rs), n)
+		}
+		for i, r := range rs {
+			expect := parallelRecord{ID: uint32(i), Value: uint16(i), Code: uint8(i % 100)}
+			if r != expect {
+				t.Errorf("workers=%d: %d: mismatch given=%+v expect=%+v", workers, i, r, expect)
+				break
+			}
+		}
+	}
+}
+
+func TestDecodeSliceNested(t *testing.T) {
+	type Head struct {
+		Type uint8
+		_    [1]byte
+	}
+	type R struct {
+		Head
+		Points [2]struct {
+			X uint16 `endian:"LE"`
+			Y uint16
+		}
+		Skip [2]byte `endian:"skip"`
+		Code uint8   `endian:"LE"`
+	}
+	raw := parallelRaw(130)
+
+	var expect [80]R
+	if err := endian.Unmarshal(raw, endian.BigEndian, &expect); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	var rs []R
+	if err := endian.DecodeSlice(raw, endian.BigEndian, &rs, 4); err != nil {
+		t.Fatalf("endian.DecodeSlice err=%s", err)
+	}
+	if len(rs) != len(expect) {
+		t.Fatalf("length mismatch given=%d expect=%d", len(rs), len(expect))
+	}
+	for i := range rs {
+		if rs[i] != expect[i] {
+			t.Errorf("%d: mismatch given=%+v expect=%+v", i, rs[i], expect[i])
+		}
+	}
+}
+
+func TestDecodeSliceError(t *testing.T) {
+	raw := parallelRaw(1000)
+	raw[700*8+6] = 'x'
+	raw[300*8+7] = 'y'
+
+	rs := []parallelRecord{{ID: 1}}
+	err := endian.DecodeSlice(raw, endian.LittleEndian, &rs, 4)
+	var rerr *endian.RecordError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("RecordError is not returned. err=%v", err)
+	}
+	if rerr.Index != 300 {
+		t.Errorf("index mismatch given=%d expect=300", rerr.Index)
+	}
+	var derr *endian.DigitError
+	if !errors.As(err, &derr) {
+		t.Errorf("DigitError is not wrapped. err=%v", err)
+	}
+	if len(rs) != 1 || rs[0].ID != 1 {
+		t.Errorf("dst is changed. given=%+v", rs)
+	}
+
+	if err := endian.DecodeSlice(raw[:15], endian.LittleEndian, &rs, 1); err == nil {
+		t.Errorf("error is not returned")
+	}
+	var vs []struct{ B []byte }
+	if err := endian.DecodeSlice(raw, endian.LittleEndian, &vs, 1); !errors.Is(err, endian.ErrVariableOffset) {
+		t.Errorf("ErrVariableOffset is not returned. err=%v", err)
+	}
+	if err := endian.DecodeSlice(raw, endian.LittleEndian, rs, 1); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func BenchmarkDecodeSlice(b *testing.B) {
+	raw := parallelRaw(1 << 14)
+	b.SetBytes(int64(len(raw)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var rs []parallelRecord
+		if err := endian.DecodeSlice(raw, endian.LittleEndian, &rs, 0); err != nil {
+			b.Fatalf("error:%s", err)
+		}
+	}
+}
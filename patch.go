@@ -0,0 +1,80 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// PatchField encodes value as the field of v pointed by path and writes it to w at base plus the offset of the field.
+// v is used to compute the layout. Other fields are not written.
+// The struct tag of the field and the byte order of the field are applied as Write.
+// It is useful to update a counter or a checksum in a large image.
+// Only the fields along path are visited, so the cost does not depend on the number of elements of v.
+func PatchField(w io.WriterAt, base int64, order ByteOrder, v interface{}, path string, value interface{}) error {
+	return PatchFieldWithOptions(w, base, order, v, path, value, nil)
+}
+
+// PatchFieldWithOptions is like PatchField but the behavior is customized by opts.
+// If opts is nil, it is same as PatchField.
+func PatchFieldWithOptions(w io.WriterAt, base int64, order ByteOrder, v interface{}, path string, value interface{}, opts *Options) error {
+	l, err := lookupLayout(v, path, opts)
+	if err != nil {
+		return err
+	}
+	rv, size, err := layoutValue(l, value)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, size)
+	if err := encodeLayout(b, order, l, rv); err != nil {
+		return err
+	}
+	_, err = w.WriteAt(b, base+int64(l.Offset))
+	return err
+}
+
+// layoutValue returns value as a value of the field l and the encoded size.
+func layoutValue(l *Layout, value interface{}) (reflect.Value, int, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr && rv.Type().Elem() == l.Type {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || !rv.Type().AssignableTo(l.Type) {
+		return reflect.Value{}, 0, fmt.Errorf("endian: %T is not assignable to %s", value, l.Type)
+	}
+	size := sizeOfField(rv, l.cnf, l.opts)
+	if size != l.Size && (l.cnf == nil || !l.cnf.rest) {
+		return reflect.Value{}, 0, fmt.Errorf("endian: size of value %d byte mismatches size of field %d byte", size, l.Size)
+	}
+	return rv, size, nil
+}
+
+// encodeLayout encodes rv as the field l to b.
+func encodeLayout(b []byte, order ByteOrder, l *Layout, rv reflect.Value) error {
+	if l.Order != nil {
+		order = l.Order
+	}
+	index := 0
+	err := writeField(rv, order, l.cnf, b, &index, l.opts)
+	if err != nil && err != errCannotInterface {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type bufferAt []byte
+
+func (b bufferAt) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b[off:], p), nil
+}
+
+func TestPatchField(t *testing.T) {
+	type Entry struct {
+		Flags uint16
+		Temp  float64 `endian:"scale=0.01,offset=-40"`
+	}
+	type Header struct {
+		Count   uint32 `endian:"BE"`
+		Entries [3]Entry
+	}
+	type S struct {
+		Magic  [4]byte
+		Header `endian:"raw"`
+		ID     [4]byte
+	}
+
+	s := S{Magic: [4]byte{'M', 'A', 'G', 'C'}}
+	raw, err := endian.Marshal(endian.LittleEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	base := 3
+	buf := make(bufferAt, base+len(raw))
+	copy(buf[base:], raw)
+
+	patches := []struct {
+		path  string
+		value interface{}
+	}{
+		{"Header.Count", uint32(0x01020304)},
+		{"Entries[1].Flags", uint16(0xabcd)},
+		{"Header.Entries[2].Temp", 25.5},
+		{"ID", &[4]byte{1, 2, 3, 4}},
+	}
+	for _, p := range patches {
+		err := endian.PatchField(buf, int64(base), endian.LittleEndian, &s, p.path, p.value)
+		if err != nil {
+			t.Fatalf("%s: endian.PatchField err=%s", p.path, err)
+		}
+	}
+
+	s.Count = 0x01020304
+	s.Entries[1].Flags = 0xabcd
+	s.Entries[2].Temp = 25.5
+	s.ID = [4]byte{1, 2, 3, 4}
+	expect, err := endian.Marshal(endian.LittleEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(buf[base:], expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", []byte(buf[base:]), expect)
+	}
+	if bytes.Compare(buf[:base], make([]byte, base)) != 0 {
+		t.Errorf("bytes before base are modified. given=%x", []byte(buf[:base]))
+	}
+
+	if err := endian.PatchField(buf, 0, endian.BigEndian, &s, "Header.Count", uint16(1)); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.PatchField(buf, 0, endian.BigEndian, &s, "Header.Entries[2].Temp", 1000.0); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestPatchFieldSlice(t *testing.T) {
+	type S struct {
+		Len  uint8
+		Data []byte
+	}
+	s := S{Data: make([]byte, 2)}
+	buf := make(bufferAt, 3)
+	if err := endian.PatchField(buf, 0, endian.LittleEndian, &s, "Data", []byte{1, 2, 3}); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.PatchField(buf, 0, endian.LittleEndian, &s, "Data", []byte{1, 2}); err != nil {
+		t.Fatalf("endian.PatchField err=%s", err)
+	}
+	if bytes.Compare(buf, []byte{0, 1, 2}) != 0 {
+		t.Errorf("mismatch given=%x", []byte(buf))
+	}
+}
+
+func TestPatchFieldLarge(t *testing.T) {
+	type Entry struct {
+		Flags uint16
+		Count uint32
+	}
+	type Image struct {
+		Magic   uint32
+		Entries [1 << 20]Entry
+	}
+	s := new(Image)
+	buf := make(bufferAt, 64)
+
+	if err := endian.PatchField(buf, 0, endian.LittleEndian, s, "Entries[2].Count", uint32(0x01020304)); err != nil {
+		t.Fatalf("endian.PatchField err=%s", err)
+	}
+	if expect := []byte{0x04, 0x03, 0x02, 0x01}; bytes.Compare(buf[18:22], expect) != 0 {
+		t.Errorf("mismatch given=%x expect=%x", buf[18:22], expect)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		endian.PatchField(buf, 0, endian.LittleEndian, s, "Entries[3].Flags", uint16(1))
+	})
+	if allocs > 100 {
+		t.Errorf("too many allocations given=%v", allocs)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// plan is the precomputed information to decode and encode a value of a type.
+// It is immutable and shared across goroutines.
+// Struct tags of fixed size types are parsed once and the fields are decoded by the plan.
+type plan struct {
+	size     int  // encoded size of the zero value
+	variable bool // the size depends on the value
+
+	fields []fieldPlan // fields of a struct
+	elem   *plan       // plan of elements of an array of structs
+}
+
+// fieldPlan is the precomputed information of a struct field.
+type fieldPlan struct {
+	index    int
+	field    reflect.StructField
+	cnf      *tagConfig
+	order    ByteOrder // byte order of the tag. nil if it follows the struct.
+	size     int
+	skipped  bool  // only updates offset
+	embedded bool  // flattened embedded struct
+	sub      *plan // plan of the field value which is decoded by the plan. nil if it is a leaf.
+}
+
+// plans caches plan per type. It is cleared by RegisterCodec since codecs change sizes.
+var plans sync.Map
+
+// planOf returns the plan of t.
+// It is cached unless opts overrides codecs.
+func planOf(t reflect.Type, opts *Options) (*plan, error) {
+	if len(opts.Codecs) == 0 {
+		if p, ok := plans.Load(t); ok {
+			return p.(*plan), nil
+		}
+	}
+	if err := checkEmbedded(t, opts); err != nil {
+		return nil, fmt.Errorf("%s: %w", t, err)
+	}
+	return newPlan(t, opts), nil
+}
+
+// newPlan is like planOf but t must be checked by checkEmbedded.
+func newPlan(t reflect.Type, opts *Options) *plan {
+	if len(opts.Codecs) == 0 {
+		if p, ok := plans.Load(t); ok {
+			return p.(*plan)
+		}
+	}
+	p := &plan{
+		size:     sizeOfValue(reflect.New(t).Elem(), true, opts),
+		variable: isVariableType(t, opts),
+	}
+	if !p.variable {
+		compilePlan(p, t, opts)
+	}
+	if len(opts.Codecs) == 0 {
+		plans.Store(t, p)
+	}
+	return p
+}
+
+// compilePlan fills fields or elem of p whose type is t.
+// Values which are not compiled are decoded by read.
+func compilePlan(p *plan, t reflect.Type, opts *Options) {
+	if opts.lookupCodec(t) != nil || t == timeType || isBigInt(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		zero := reflect.New(t).Elem()
+		p.fields = []fieldPlan{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			cnf, err := parseStructTag(f.Tag)
+			if err != nil {
+				/* read reports the error */
+				p.fields = nil
+				return
+			}
+			if cnf != nil && cnf.ignore {
+				continue
+			}
+			fp := fieldPlan{
+				index:   i,
+				field:   f,
+				cnf:     cnf,
+				order:   cnf.byteOrder(nil),
+				size:    sizeOfField(fieldValue(zero, i), cnf, opts),
+				skipped: cnf.skipped(),
+			}
+			ft := f.Type
+			if isEmbeddedStruct(f) && opts.lookupCodec(ft) == nil {
+				fp.embedded = true
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+			}
+			if !fp.skipped && !cnf.encoded() && opts.lookupCodec(ft) == nil {
+				if sub := newPlan(ft, opts); sub.fields != nil || sub.elem != nil {
+					fp.sub = sub
+				}
+			}
+			p.fields = append(p.fields, fp)
+		}
+	case reflect.Array:
+		if t.Len() == 0 {
+			return
+		}
+		if elem := newPlan(t.Elem(), opts); elem.fields != nil || elem.elem != nil {
+			p.elem = elem
+		}
+	}
+}
+
+// decode reads from b and fill v by the plan. It is same as read.
+func (p *plan) decode(b []byte, order ByteOrder, v reflect.Value, index *int, opts *Options) error {
+	switch {
+	case p.fields != nil:
+		return p.decodeStruct(b, order, v, index, opts)
+	case p.elem != nil:
+		for i := 0; i < v.Len(); i++ {
+			if err := p.elem.decode(b, order, v.Index(i), index, opts); err != nil && err != errCannotInterface {
+				return err
+			}
+		}
+		return nil
+	}
+	return read(b, order, v, index, opts)
+}
+
+// decodeStruct reads fields of a struct v. It is same as readStruct.
+func (p *plan) decodeStruct(b []byte, order ByteOrder, v reflect.Value, index *int, opts *Options) error {
+	for i := range p.fields {
+		fp := &p.fields[i]
+		if fp.skipped {
+			*index += fp.size
+			continue
+		}
+		fv := v.Field(fp.index)
+		if opts.Unexported && fp.field.PkgPath != "" && fp.field.Name != "_" {
+			fv = exportField(fv)
+		} else if opts.Strict && isUnexported(fp.field) {
+			return fmt.Errorf("%s.%s: %w", v.Type(), fp.field.Name, ErrUnexportedField)
+		}
+		forder := order
+		if fp.order != nil {
+			forder = fp.order
+		}
+		fopts := opts.withTag(fp.cnf)
+
+		var err error
+		switch {
+		case fp.embedded:
+			ev, eerr := embeddedStruct(fv, true)
+			if eerr != nil {
+				return fmt.Errorf("%s.%s: %w", v.Type(), fp.field.Name, eerr)
+			}
+			if fp.sub != nil {
+				err = fp.sub.decodeStruct(b, forder, ev, index, fopts)
+			} else {
+				err = readStruct(b, forder, ev, index, fopts)
+			}
+		case fp.sub != nil && !fv.CanInterface():
+			/* skip unexported field */
+			*index += fp.size
+		case fp.sub != nil:
+			err = fp.sub.decode(b, forder, fv, index, fopts)
+		default:
+			err = readField(b, forder, fv, fp.cnf, index, fopts)
+		}
+		if err != nil && err != errCannotInterface {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearPlans removes all cached plans.
+func clearPlans() {
+	plans.Range(func(k, _ interface{}) bool {
+		plans.Delete(k)
+		return true
+	})
+}
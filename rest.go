@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+var errRestNotLast = errors.New("rest field must be the last field")
+
+func isByteSlice(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// sizeOfRest returns the size of a rest field. It is the length of current value.
+func sizeOfRest(v reflect.Value) int {
+	if !isByteSlice(v) {
+		return 0
+	}
+	return v.Len()
+}
+
+// checkRest returns errRestNotLast if a rest field of t is not at the end of the encoded value.
+// A rest field must be the last field of the top-level struct, or of its last field recursively,
+// since it consumes all remaining bytes. last reports whether t is at the end.
+// Recursive embedded structs are also rejected since they can not be walked.
+func checkRest(t reflect.Type, last bool, opts *Options) error {
+	if err := checkEmbedded(t, opts); err != nil {
+		return err
+	}
+	return checkRestRecursive(t, last, opts, map[reflect.Type]bool{})
+}
+
+func checkRestRecursive(t reflect.Type, last bool, opts *Options, seen map[reflect.Type]bool) error {
+	if !last {
+		/* the result doesn't depend on the position. it also stops recursive types. */
+		if seen[t] {
+			return nil
+		}
+		seen[t] = true
+	}
+	if opts.lookupCodec(t) != nil || t == timeType || isBigInt(t) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice:
+		/* elements are repeated */
+		return checkRestRecursive(t.Elem(), false, opts, seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			cnf, _ := parseStructTag(f.Tag)
+			if cnf != nil && cnf.ignore {
+				continue
+			}
+			fieldLast := last && i == t.NumField()-1
+			if cnf != nil && cnf.rest {
+				if !fieldLast {
+					return fmt.Errorf("%s.%s: %w", t, f.Name, errRestNotLast)
+				}
+				continue
+			}
+			if cnf.encoded() {
+				continue
+			}
+			ft := f.Type
+			if isEmbeddedStruct(f) && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if err := checkRestRecursive(ft, fieldLast, opts, seen); err != nil {
+				return fmt.Errorf("%s.%s: %w", t, f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// clearRest sets nil to the rest field of v and reports whether v has a rest field.
+// The rest field is searched in the last fields recursively. See checkRest.
+func clearRest(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct || v.NumField() == 0 {
+		return false
+	}
+	i := v.NumField() - 1
+	cnf, _ := parseStructTag(v.Type().Field(i).Tag)
+	if cnf != nil && cnf.ignore {
+		return false
+	}
+	if cnf != nil && cnf.rest {
+		if v.Field(i).CanSet() && isByteSlice(v.Field(i)) {
+			v.Field(i).Set(reflect.Zero(v.Field(i).Type()))
+		}
+		return true
+	}
+	return clearRest(fieldValue(v, i))
+}
+
+// readAll reads all from r. The size is limited by opts.MaxAlloc.
+func readAll(r io.Reader, opts *Options) ([]byte, error) {
+	if opts.MaxAlloc > 0 {
+		r = io.LimitReader(r, int64(opts.MaxAlloc)+1)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.checkAlloc(len(b)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// readRest reads all remaining bytes of b into v.
+func readRest(b []byte, v reflect.Value, index *int) error {
+	if !isByteSlice(v) {
+		return fmt.Errorf("Not Supported rest %s", v.Type())
+	}
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	ret := make([]byte, len(b)-*index)
+	copy(ret, b[*index:])
+	v.SetBytes(ret)
+	*index = len(b)
+	return nil
+}
+
+// writeRest writes v to b verbatim.
+func writeRest(v reflect.Value, b []byte, index *int) error {
+	if !isByteSlice(v) {
+		return fmt.Errorf("Not Supported rest %s", v.Type())
+	}
+	*index += copy(b[*index:], v.Bytes())
+	return nil
+}
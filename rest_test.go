@@ -0,0 +1,163 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type restRecord struct {
+	Type    uint8
+	Len     uint16
+	Payload []byte `endian:"rest"`
+}
+
+func TestReadRest(t *testing.T) {
+	raw := []byte{0x01, 0x00, 0x04, 0xaa, 0xbb, 0xcc, 0xdd}
+
+	var r restRecord
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &r); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if r.Type != 0x01 || r.Len != 4 {
+		t.Errorf("header mismatch given=%+v", r)
+	}
+	if expect := raw[3:]; bytes.Compare(r.Payload, expect) != 0 {
+		t.Errorf("Payload mismatch given=%x expect=%x", r.Payload, expect)
+	}
+
+	// byte slice
+	r = restRecord{Payload: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}}
+	if err := endian.Unmarshal(raw[:5], endian.BigEndian, &r); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if expect := raw[3:5]; bytes.Compare(r.Payload, expect) != 0 {
+		t.Errorf("Payload mismatch given=%x expect=%x", r.Payload, expect)
+	}
+
+	// empty
+	if err := endian.Unmarshal(raw[:3], endian.BigEndian, &r); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if len(r.Payload) != 0 {
+		t.Errorf("Payload is not empty given=%x", r.Payload)
+	}
+
+	// short
+	if err := endian.Unmarshal(raw[:2], endian.BigEndian, &r); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	// MaxAlloc
+	opts := &endian.Options{MaxAlloc: 4}
+	if err := endian.ReadWithOptions(bytes.NewBuffer(raw), endian.BigEndian, &r, opts); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestWriteRest(t *testing.T) {
+	r := restRecord{Type: 0x02, Len: 3, Payload: []byte{0x11, 0x22, 0x33}}
+	expect := []byte{0x02, 0x00, 0x03, 0x11, 0x22, 0x33}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err := endian.Write(buf, endian.BigEndian, r); err != nil {
+		t.Fatalf("endian.Write err=%s", err)
+	}
+	if ret := buf.Bytes(); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, &r)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+}
+
+func TestRestNotLast(t *testing.T) {
+	type S struct {
+		Payload []byte `endian:"rest"`
+		B       byte
+	}
+
+	var s S
+	if err := endian.Unmarshal([]byte{0x01, 0x02}, endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestRestNested(t *testing.T) {
+	type Inner struct {
+		Len  uint8
+		Body []byte `endian:"rest"`
+	}
+	type Base struct {
+		Len  uint8
+		Body []byte `endian:"rest"`
+	}
+	type Nested struct {
+		In   Inner
+		Tail uint16
+	}
+	type Embedded struct {
+		Base
+		Tail byte
+	}
+	type Elems struct {
+		In [2]Inner
+	}
+	raw := []byte{0x01, 0x02, 0x03, 0x04}
+
+	for _, data := range []interface{}{&Nested{}, &Embedded{}, &Elems{}} {
+		if err := endian.Unmarshal(raw, endian.BigEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if err := endian.Read(bytes.NewReader(raw), endian.BigEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if _, err := endian.Marshal(endian.BigEndian, data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+		if _, err := endian.LayoutOf(data); err == nil {
+			t.Errorf("%T: error is not returned", data)
+		}
+	}
+
+	/* rest field in the last field consumes all remaining bytes */
+	type Last struct {
+		Type uint8
+		In   Inner
+	}
+	var s Last
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Type != 1 || s.In.Len != 2 || bytes.Compare(s.In.Body, []byte{0x03, 0x04}) != 0 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Marshal(endian.BigEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
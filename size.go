@@ -20,21 +20,29 @@ import (
 	"reflect"
 )
 
-func sizeOfValueRecursive(c *int, v reflect.Value, structtag bool) {
+func sizeOfValueRecursive(c *int, v reflect.Value, structtag bool, opts *Options) {
+	if codec := opts.lookupCodec(v.Type()); codec != nil {
+		*c += codec.Size()
+		return
+	}
+	if v.Type() == timeType || isBigInt(v.Type()) {
+		/* time.Time requires time tag and big.Int requires size tag */
+		return
+	}
 	switch v.Kind() {
 	case reflect.Struct:
 		if structtag {
 			for i := 0; i < v.Type().NumField(); i++ {
 				f := v.Type().Field(i)
-				cnf := parseStructTag(f.Tag)
+				cnf, _ := parseStructTag(f.Tag)
 				if cnf != nil && cnf.ignore {
 					continue
 				}
-				sizeOfValueRecursive(c, v.Field(i), structtag)
+				*c += sizeOfField(fieldValue(v, i), cnf, opts)
 			}
 		} else {
 			for i := 0; i < v.NumField(); i++ {
-				sizeOfValueRecursive(c, v.Field(i), structtag)
+				sizeOfValueRecursive(c, fieldValue(v, i), structtag, opts)
 			}
 		}
 	case reflect.Array, reflect.Slice:
@@ -42,15 +50,51 @@ func sizeOfValueRecursive(c *int, v reflect.Value, structtag bool) {
 			return
 		}
 		var elemSize int
-		sizeOfValueRecursive(&elemSize, v.Index(0), structtag)
+		sizeOfValueRecursive(&elemSize, v.Index(0), structtag, opts)
 		*c += (elemSize * v.Len())
-	default:
-		/* other types */
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
 		*c += v.Type().Bits() / 8
+	default:
+		/* other types. e.g. string without size tag */
 	}
 }
 
-func sizeOfValue(v reflect.Value, structtag bool) (ret int) {
-	sizeOfValueRecursive(&ret, v, structtag)
+func sizeOfValue(v reflect.Value, structtag bool, opts *Options) (ret int) {
+	sizeOfValueRecursive(&ret, v, structtag, optionsOrDefault(opts))
 	return ret
 }
+
+// sizeOfField returns the size of a struct field v which has the struct tag cnf.
+func sizeOfField(v reflect.Value, cnf *tagConfig, opts *Options) int {
+	if codec := opts.lookupCodec(v.Type()); codec != nil {
+		return codec.Size()
+	}
+	if cnf != nil && cnf.rest {
+		return sizeOfRest(v)
+	}
+	if cnf.hasScale() {
+		return sizeOfScaled(cnf)
+	}
+	if cnf.hasFloat() {
+		return sizeOfFloat(cnf)
+	}
+	if cnf.hasDigit() {
+		return sizeOfDigit(v, cnf)
+	}
+	if isBigInt(v.Type()) {
+		return sizeOfBigInt(cnf)
+	}
+	if cnf.hasAddr() {
+		return sizeOfAddr(cnf)
+	}
+	if v.Type() == timeType && cnf.hasTime() {
+		return sizeOfTime(cnf)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return sizeOfString(v, cnf)
+	}
+	return sizeOfValue(v, true, opts)
+}
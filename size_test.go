@@ -30,12 +30,12 @@ func TestSizeOfValue(t *testing.T) {
 	a := A{}
 	a.Byte = make([]byte, 7)
 	val := reflect.ValueOf(a)
-	size := sizeOfValue(val, false)
+	size := sizeOfValue(val, false, nil)
 	if size != 15 {
 		t.Errorf("size mismatch given=%d expect 15", size)
 	}
 
-	size = sizeOfValue(val, true)
+	size = sizeOfValue(val, true, nil)
 	if size != 8 {
 		t.Errorf("size mismatch given=%d expect 8", size)
 	}
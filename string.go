@@ -0,0 +1,306 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	String_Type_UTF8 = iota
+	String_Type_UTF16
+	String_Type_LATIN1
+)
+
+// errStringResized is returned by readString if the length of a variable length string is changed.
+// The offsets of following fields are changed, so the value must be decoded again.
+var errStringResized = errors.New("length of string is changed")
+
+// isVarString reports whether the string field is prefixed by the length without size tag.
+// The size depends on the value.
+func (c *tagConfig) isVarString() bool {
+	return c != nil && c.prefix > 0 && c.size == 0
+}
+
+// sizeOfString returns the size of a string field v which has the struct tag cnf.
+// It includes the length prefix.
+func sizeOfString(v reflect.Value, cnf *tagConfig) int {
+	if cnf.isVarString() {
+		return cnf.prefix + encodedLen(v.String(), cnf)
+	}
+	if cnf == nil || cnf.size == 0 {
+		return 0
+	}
+	return cnf.prefix + cnf.size
+}
+
+// clearVarString sets "" to length prefixed strings without size tag in v.
+// Read sizes the input by the current value, so it must not read more than the minimum size.
+func clearVarString(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType || isBigInt(v.Type()) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			cnf, _ := parseStructTag(v.Type().Field(i).Tag)
+			if cnf != nil && cnf.ignore {
+				continue
+			}
+			fv := fieldValue(v, i)
+			if cnf.isVarString() && fv.Kind() == reflect.String {
+				if fv.CanSet() {
+					fv.SetString("")
+				}
+				continue
+			}
+			clearVarString(fv)
+		}
+	case reflect.Array, reflect.Slice:
+		if v.Len() == 0 || v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			clearVarString(v.Index(i))
+		}
+	}
+}
+
+// hasVarString reports whether a value of t has a length prefixed string without size tag.
+func hasVarString(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			cnf, _ := parseStructTag(f.Tag)
+			if cnf != nil && cnf.ignore {
+				continue
+			}
+			if cnf.isVarString() && f.Type.Kind() == reflect.String {
+				return true
+			}
+			ft := f.Type
+			if isEmbeddedStruct(f) && ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if hasVarString(ft, seen) {
+				return true
+			}
+		}
+	case reflect.Array, reflect.Slice:
+		return hasVarString(t.Elem(), seen)
+	}
+	return false
+}
+
+// encodedLen returns the size in bytes of str in the encoding of the tag.
+func encodedLen(str string, cnf *tagConfig) int {
+	switch cnf.str {
+	case String_Type_UTF16:
+		n := 0
+		for _, r := range str {
+			if r >= 0x10000 {
+				n += 4
+			} else {
+				n += 2
+			}
+		}
+		return n
+	case String_Type_LATIN1:
+		return utf8.RuneCountInString(str)
+	}
+	return len(str)
+}
+
+// errStringSize returns an error if a string field can not be handled.
+// It returns nil and the field is ignored if opts.String is StringIgnore.
+func errStringSize(opts *Options) error {
+	if opts.String == StringIgnore {
+		return nil
+	}
+	return fmt.Errorf("Not Supported %s without size tag", reflect.String)
+}
+
+// unitSize returns the size of a code unit of the string encoding.
+func (c *tagConfig) unitSize() int {
+	if c.str == String_Type_UTF16 {
+		return 2
+	}
+	return 1
+}
+
+// decodeString decodes bs. It stops at NUL unless the string is length prefixed.
+func decodeString(bs []byte, order ByteOrder, cnf *tagConfig) string {
+	padded := cnf.prefix == 0
+	switch cnf.str {
+	case String_Type_UTF16:
+		units := make([]uint16, 0, len(bs)/2)
+		for i := 0; i+1 < len(bs); i += 2 {
+			u := order.Uint16(bs[i : i+2])
+			if u == 0 && padded {
+				break
+			}
+			units = append(units, u)
+		}
+		return string(utf16.Decode(units))
+	case String_Type_LATIN1:
+		rs := make([]rune, 0, len(bs))
+		for _, c := range bs {
+			if c == 0 && padded {
+				break
+			}
+			rs = append(rs, rune(c))
+		}
+		return string(rs)
+	}
+	if padded {
+		if i := bytes.IndexByte(bs, 0); i >= 0 {
+			return string(bs[:i])
+		}
+	}
+	return string(bs)
+}
+
+// encodeString encodes str in the encoding of the tag.
+func encodeString(str string, order ByteOrder, cnf *tagConfig) ([]byte, error) {
+	switch cnf.str {
+	case String_Type_UTF16:
+		units := utf16.Encode([]rune(str))
+		ret := make([]byte, len(units)*2)
+		for i, u := range units {
+			order.PutUint16(ret[i*2:], u)
+		}
+		return ret, nil
+	case String_Type_LATIN1:
+		ret := make([]byte, 0, len(str))
+		for _, r := range str {
+			if r > 0xff {
+				return nil, fmt.Errorf("%q can not be encoded as latin1", r)
+			}
+			ret = append(ret, byte(r))
+		}
+		return ret, nil
+	}
+	return []byte(str), nil
+}
+
+// readString reads a string from b.
+// The string is NUL padded or prefixed by the length in code units.
+// If a variable length string is resized, errStringResized is returned after v is set.
+func readString(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int, opts *Options) error {
+	if cnf.isVarString() {
+		return readVarString(b, order, v, cnf, index, opts)
+	}
+	size := sizeOfString(v, cnf)
+	if size == 0 {
+		return errStringSize(opts)
+	}
+	bs := b[*index+cnf.prefix : *index+size]
+	if cnf.prefix > 0 {
+		n, err := readUint(b[*index:*index+cnf.prefix], order, cnf.prefix)
+		if err != nil {
+			return err
+		}
+		if n*uint64(cnf.unitSize()) > uint64(cnf.size) {
+			return fmt.Errorf("string length %d exceeds size %d", n, cnf.size)
+		}
+		bs = bs[:int(n)*cnf.unitSize()]
+	}
+	*index += size
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.SetString(decodeString(bs, order, cnf))
+	return nil
+}
+
+// readVarString reads a string which is prefixed by the length and has no size tag.
+// If b is shorter than the string, v is set to a string of the length and errStringResized is returned
+// so that the caller can retry with the required size.
+func readVarString(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int, opts *Options) error {
+	n, err := readUint(b[*index:*index+cnf.prefix], order, cnf.prefix)
+	if err != nil {
+		return err
+	}
+	start := *index + cnf.prefix
+	if n > uint64(int(^uint(0)>>1)-start)/uint64(cnf.unitSize()) {
+		return fmt.Errorf("string length %d overflows", n)
+	}
+	end := start + int(n)*cnf.unitSize()
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	if end > len(b) {
+		/* more bytes are required. the placeholder has the encoded size of the string. */
+		if err := opts.checkAlloc(end); err != nil {
+			return err
+		}
+		*index = end
+		v.SetString(strings.Repeat("\x00", int(n)))
+		return errStringResized
+	}
+	*index = end
+	old := encodedLen(v.String(), cnf)
+	v.SetString(decodeString(b[start:end], order, cnf))
+	if encodedLen(v.String(), cnf) != old {
+		return errStringResized
+	}
+	return nil
+}
+
+// writeString writes a string to b.
+// The string is padded by NUL and prefixed by the length in code units if prefix tag is defined.
+// A string which has prefix tag without size tag is written without padding.
+func writeString(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int, opts *Options) error {
+	size := sizeOfString(v, cnf)
+	if size == 0 && !cnf.isVarString() {
+		return errStringSize(opts)
+	}
+	str, err := encodeString(v.String(), order, cnf)
+	if err != nil {
+		return err
+	}
+	if cnf.isVarString() {
+		if n := uint64(len(str) / cnf.unitSize()); cnf.prefix < 8 && n >= 1<<uint(cnf.prefix*8) {
+			return fmt.Errorf("string length %d overflows %d byte prefix", n, cnf.prefix)
+		}
+	} else if len(str) > cnf.size {
+		return fmt.Errorf("string is too long: len=%d size=%d", len(str), cnf.size)
+	}
+	if cnf.prefix > 0 {
+		if err := putUint(b[*index:*index+cnf.prefix], order, cnf.prefix, uint64(len(str)/cnf.unitSize())); err != nil {
+			return err
+		}
+	}
+	bs := b[*index+cnf.prefix : *index+size]
+	n := copy(bs, str)
+	for i := n; i < len(bs); i++ {
+		bs[i] = 0
+	}
+	*index += size
+	return nil
+}
@@ -0,0 +1,274 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nokute78/go-endian"
+	"strings"
+	"testing"
+)
+
+func TestReadString(t *testing.T) {
+	type S struct {
+		Len  uint16
+		Name string `endian:"size=8"`
+	}
+
+	var s S
+	raw := []byte{0x00, 0x04, 'n', 'a', 'm', 'e', 0x00, 0x00, 0x00, 0x00}
+	if err := endian.Read(bytes.NewBuffer(raw), endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if s.Len != 4 {
+		t.Errorf("Len mismatch given=%d expect=4", s.Len)
+	}
+	if s.Name != "name" {
+		t.Errorf("Name mismatch given=%q expect=%q", s.Name, "name")
+	}
+}
+
+func TestWriteString(t *testing.T) {
+	type S struct {
+		Name string `endian:"size=6"`
+		B    byte
+	}
+
+	s := S{Name: "abc", B: 0xff}
+	buf := bytes.NewBuffer([]byte{})
+	if err := endian.Write(buf, endian.LittleEndian, s); err != nil {
+		t.Fatalf("endian.Write err=%s", err)
+	}
+	expect := []byte{'a', 'b', 'c', 0x00, 0x00, 0x00, 0xff}
+	if ret := buf.Bytes(); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	s.Name = "too long"
+	if err := endian.Write(buf, endian.LittleEndian, s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestStringUTF16(t *testing.T) {
+	type GPTEntry struct {
+		Attrs uint64
+		Name  string `endian:"utf16,size=72"`
+	}
+
+	e := GPTEntry{Attrs: 1, Name: "EFI system partition"}
+	ret, err := endian.Marshal(endian.LittleEndian, e)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if len(ret) != 80 {
+		t.Fatalf("size mismatch given=%d expect=80", len(ret))
+	}
+	if expect := []byte{'E', 0, 'F', 0, 'I', 0, ' ', 0}; bytes.Compare(ret[8:16], expect) != 0 {
+		t.Errorf("mismatch given=%x expect=%x", ret[8:16], expect)
+	}
+
+	var e2 GPTEntry
+	if err := endian.Unmarshal(ret, endian.LittleEndian, &e2); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if e2 != e {
+		t.Errorf("mismatch given=%+v expect=%+v", e2, e)
+	}
+
+	// big endian and surrogate pair
+	type S struct {
+		Name string `endian:"utf16,BE,size=8"`
+	}
+	raw := []byte{0x00, 'a', 0xd8, 0x3d, 0xde, 0x00, 0x00, 0x00}
+	var s S
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Name != "a\U0001F600" {
+		t.Errorf("mismatch given=%q", s.Name)
+	}
+	ret, err = endian.Marshal(endian.LittleEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestStringLatin1(t *testing.T) {
+	type S struct {
+		Name string `endian:"latin1,size=4"`
+	}
+	raw := []byte{'c', 'a', 'f', 0xe9}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Name != "caf\u00e9" {
+		t.Errorf("mismatch given=%q", s.Name)
+	}
+	ret, err := endian.Marshal(endian.LittleEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	s.Name = "\u3042"
+	if _, err := endian.Marshal(endian.LittleEndian, s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestStringPrefix(t *testing.T) {
+	type S struct {
+		Pascal  string `endian:"prefix=u8,size=7"`
+		Unicode string `endian:"utf16,prefix=u16,size=6"`
+	}
+	raw := []byte{
+		0x03, 'a', 0x00, 'c', 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x02, 0x00, 'x', 0x00, 'y', 0x00, 0x00,
+	}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.Pascal != "a\x00c" || s.Unicode != "xy" {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	raw[0] = 8
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestStringVarPrefix(t *testing.T) {
+	type S struct {
+		A    string `endian:"prefix=u8"`
+		B    string `endian:"prefix=u16,utf16,BE"`
+		C    string `endian:"prefix=u32,latin1"`
+		Tail uint16
+	}
+	raw := []byte{
+		0x03, 'a', 'b', 'c',
+		0x00, 0x02, 0x00, 'h', 0x00, 'i',
+		0x01, 0x00, 0x00, 0x00, 0xe9,
+		0x34, 0x12,
+	}
+	expect := S{A: "abc", B: "hi", C: "\u00e9", Tail: 0x1234}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s != expect {
+		t.Errorf("mismatch given=%+v expect=%+v", s, expect)
+	}
+
+	/* stream has following data */
+	r := bytes.NewReader(append(raw, 0xff))
+	s = S{A: "longer value"}
+	if err := endian.Read(r, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if s != expect {
+		t.Errorf("mismatch given=%+v expect=%+v", s, expect)
+	}
+	if r.Len() != 1 {
+		t.Errorf("remaining mismatch given=%d expect=1", r.Len())
+	}
+
+	ret, err := endian.Marshal(endian.LittleEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	if err := endian.Unmarshal(raw[:len(raw)-1], endian.LittleEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.Read(bytes.NewReader(raw[:5]), endian.LittleEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if _, _, _, err := endian.OffsetOf(&s, "Tail"); !errors.Is(err, endian.ErrVariableOffset) {
+		t.Errorf("ErrVariableOffset is not returned. err=%v", err)
+	}
+
+	s.A = strings.Repeat("a", 256)
+	if _, err := endian.Marshal(endian.LittleEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestStringVarPrefixFollowed(t *testing.T) {
+	type S struct {
+		A    byte
+		Name string `endian:"prefix=u8"`
+		B    byte
+	}
+	raw := []byte{0x01, 0x02, 'h', 'i', 0x03}
+	expect := S{A: 1, Name: "hi", B: 3}
+
+	var s S
+	r := bytes.NewReader(append(raw, 0xff))
+	if err := endian.Read(r, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Read err=%s", err)
+	}
+	if s != expect {
+		t.Errorf("mismatch given=%+v expect=%+v", s, expect)
+	}
+	if r.Len() != 1 {
+		t.Errorf("remaining mismatch given=%d expect=1", r.Len())
+	}
+
+	/* current value is longer than the input */
+	s = S{Name: "a long pre-existing value"}
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s != expect {
+		t.Errorf("mismatch given=%+v expect=%+v", s, expect)
+	}
+
+	/* input is too short */
+	if err := endian.Read(bytes.NewReader(raw[:4]), endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+	opts := &endian.Options{MaxAlloc: 4}
+	if err := endian.ReadWithOptions(bytes.NewReader(raw), endian.BigEndian, &s, opts); err == nil {
+		t.Errorf("error is not returned")
+	}
+	if err := endian.UnmarshalFields(raw[:3], endian.BigEndian, &s, "Name"); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
@@ -17,7 +17,9 @@
 package endian
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -29,41 +31,216 @@ const (
 	Endian_Type_BLANK = iota
 	Endian_Type_LE
 	Endian_Type_BE
+	Endian_Type_NATIVE
+)
+
+const (
+	Bytes_Type_BLANK = iota
+	Bytes_Type_RAW
+	Bytes_Type_REVERSE
 )
 
 // tagConfig represents StructTag.
 //   "-"   : ignore the field
 //   "skip": ignore but offset will be updated
+//   "skip,keep": the field keeps original bytes. byte arrays are copied verbatim
 //   "BE"  : the field is treated as big endian
 //   "LE"  : the field is treated as little endian
+//   "native": the field is treated as the byte order of the host
+//   "raw", "bytes": byte arrays are copied verbatim regardless of the order
+//   "reverse": byte arrays are reversed if the order is big endian
+//   "rest": the last []byte field consumes all remaining bytes
+//   "time=unix|unixms|filetime|dos|ntp|mac": time.Time field is encoded as the timestamp
+//   "ipv4", "ipv6", "mac": the field is an address in network order
+//   "fixed=Qm.n", "fixed=UQm.n": float field is encoded as a fixed point number
+//   "scale=S", "offset=O": float field is encoded as an integer. value = raw*S+O
+//   "signed": the integer of scale and big.Int is signed
+//   "float=f16|bf16|f32|f64|f80": float field is encoded in the format
+//   "bcd": integer field is encoded as packed BCD
+//   "ascii=oct|dec|hex": integer field is encoded as ASCII digits
+//   "pad=zero|space": padding of ASCII digits
+//   "nul": ASCII digits are terminated by NUL
+//   "utf16", "latin1": string field is encoded in UTF-16 in the byte order or Latin-1
+//   "prefix=u8|u16|u32": string field is prefixed by the length in code units. it is variable length without size
+//   "size=N": the field occupies N bytes. It is required for string fields.
 type tagConfig struct {
 	ignore bool
 	skip   bool
+	keep   bool
 	endian int
+	bytes  int
+	size   int
+	rest   bool
+	time   int
+	addr   int
+
+	scaled bool
+	scale  float64
+	offset float64
+	signed bool
+	float  int
+
+	digit    int
+	spacePad bool
+	nulTerm  bool
+
+	str    int
+	prefix int
 }
 
-func parseStructTag(t reflect.StructTag) *tagConfig {
+func parseStructTag(t reflect.StructTag) (*tagConfig, error) {
 	s, ok := t.Lookup(tagKeyName)
 	if !ok {
-		return nil
+		return nil, nil
 	}
 	ret := &tagConfig{}
 
 	strs := strings.Split(s, ",")
 	for _, v := range strs {
+		if kv := strings.SplitN(v, "=", 2); len(kv) == 2 {
+			if err := ret.parseKeyValue(kv[0], kv[1]); err != nil {
+				return nil, err
+			}
+			continue
+		}
 		switch v {
 		case "-":
 			ret.ignore = true
-			return ret
+			return ret, nil
 		case "skip":
 			ret.skip = true
-			return ret
+		case "keep":
+			ret.keep = true
+		case "rest":
+			ret.rest = true
+		case "ipv4":
+			ret.addr = Addr_Type_IPV4
+		case "ipv6":
+			ret.addr = Addr_Type_IPV6
+		case "mac":
+			ret.addr = Addr_Type_MAC
+		case "signed":
+			ret.signed = true
+		case "bcd":
+			ret.digit = Digit_Type_BCD
+		case "nul":
+			ret.nulTerm = true
+		case "utf16":
+			ret.str = String_Type_UTF16
+		case "latin1":
+			ret.str = String_Type_LATIN1
 		case "BE":
 			ret.endian = Endian_Type_BE
 		case "LE":
 			ret.endian = Endian_Type_LE
+		case "native":
+			ret.endian = Endian_Type_NATIVE
+		case "raw", "bytes":
+			ret.bytes = Bytes_Type_RAW
+		case "reverse":
+			ret.bytes = Bytes_Type_REVERSE
 		}
 
 	}
-	return ret
+	return ret, nil
+}
+
+// parseKeyValue parses "key=value" style tag.
+func (c *tagConfig) parseKeyValue(key, value string) error {
+	switch key {
+	case "size":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid size %q", value)
+		}
+		c.size = n
+	case "time":
+		t, ok := timeTypes[value]
+		if !ok {
+			return fmt.Errorf("invalid time %q", value)
+		}
+		c.time = t
+	case "float":
+		f, ok := floatTypes[value]
+		if !ok {
+			return fmt.Errorf("invalid float %q", value)
+		}
+		c.float = f
+	case "ascii":
+		d, ok := asciiTypes[value]
+		if !ok {
+			return fmt.Errorf("invalid ascii %q", value)
+		}
+		c.digit = d
+	case "pad":
+		switch value {
+		case "zero":
+			c.spacePad = false
+		case "space":
+			c.spacePad = true
+		default:
+			return fmt.Errorf("invalid pad %q", value)
+		}
+	case "prefix":
+		switch value {
+		case "u8":
+			c.prefix = 1
+		case "u16":
+			c.prefix = 2
+		case "u32":
+			c.prefix = 4
+		default:
+			return fmt.Errorf("invalid prefix %q", value)
+		}
+	case "fixed":
+		return c.parseFixed(value)
+	case "scale":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f == 0 {
+			return fmt.Errorf("invalid scale %q", value)
+		}
+		c.scaled = true
+		c.scale = f
+	case "offset":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset %q", value)
+		}
+		c.scaled = true
+		c.offset = f
+		if c.scale == 0 {
+			c.scale = 1
+		}
+	default:
+		return fmt.Errorf("unknown tag key %q", key)
+	}
+	return nil
+}
+
+// isUnexported reports whether f is an unexported field.
+// Blank fields and embedded structs are not treated as unexported
+// since embedded structs are flattened.
+func isUnexported(f reflect.StructField) bool {
+	return f.PkgPath != "" && f.Name != "_" && !isEmbeddedStruct(f)
+}
+
+// skipped reports whether the field is skipped and only offset is updated.
+func (c *tagConfig) skipped() bool {
+	return c != nil && c.skip && !c.keep
+}
+
+// byteOrder returns ByteOrder of the tag. It returns def if the tag doesn't specify endian.
+func (c *tagConfig) byteOrder(def ByteOrder) ByteOrder {
+	if c == nil {
+		return def
+	}
+	switch c.endian {
+	case Endian_Type_BE:
+		return BigEndian
+	case Endian_Type_LE:
+		return LittleEndian
+	case Endian_Type_NATIVE:
+		return NativeEndian
+	}
+	return def
 }
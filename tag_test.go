@@ -27,6 +27,9 @@ func TestParseStructTag(t *testing.T) {
 		Skip   bool      `endian:"skip"`
 		LE     ByteOrder `endian:"LE"`
 		BE     ByteOrder `endian:"BE"`
+		Native ByteOrder `endian:"native"`
+		Raw    []byte    `endian:"raw"`
+		Rev    []byte    `endian:"reverse"`
 	}
 
 	a := A{}
@@ -39,7 +42,11 @@ func TestParseStructTag(t *testing.T) {
 			continue
 		}
 
-		cnf := parseStructTag(f.Tag)
+		cnf, err := parseStructTag(f.Tag)
+		if err != nil {
+			t.Errorf("%d: parseStructTag error %s", i, err)
+			continue
+		}
 		if cnf == nil {
 			t.Errorf("%d: cnf is nil", i)
 			continue
@@ -66,6 +73,46 @@ func TestParseStructTag(t *testing.T) {
 				t.Errorf("%d: tag is BE but endian is not BigEndian", i)
 				continue
 			}
+		case "native":
+			if cnf.endian != Endian_Type_NATIVE {
+				t.Errorf("%d: tag is native but endian is not NativeEndian", i)
+				continue
+			}
+			if cnf.byteOrder(nil) != NativeEndian {
+				t.Errorf("%d: byteOrder is not NativeEndian", i)
+				continue
+			}
+		case "raw":
+			if cnf.bytes != Bytes_Type_RAW {
+				t.Errorf("%d: tag is raw but bytes is not raw", i)
+				continue
+			}
+		case "reverse":
+			if cnf.bytes != Bytes_Type_REVERSE {
+				t.Errorf("%d: tag is reverse but bytes is not reverse", i)
+				continue
+			}
+		}
+	}
+}
+
+func TestParseStructTagKeyValue(t *testing.T) {
+	type A struct {
+		Name string `endian:"size=16"`
+	}
+
+	f := reflect.TypeOf(A{}).Field(0)
+	cnf, err := parseStructTag(f.Tag)
+	if err != nil {
+		t.Fatalf("parseStructTag error %s", err)
+	}
+	if cnf.size != 16 {
+		t.Errorf("size mismatch given=%d expect=16", cnf.size)
+	}
+
+	for _, tag := range []reflect.StructTag{`endian:"size=0"`, `endian:"size=x"`, `endian:"unknown=1"`} {
+		if _, err := parseStructTag(tag); err == nil {
+			t.Errorf("%s: error is not returned", tag)
 		}
 	}
 }
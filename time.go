@@ -0,0 +1,210 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	Time_Type_BLANK = iota
+	Time_Type_UNIX
+	Time_Type_UNIXMS
+	Time_Type_FILETIME
+	Time_Type_DOS
+	Time_Type_NTP
+	Time_Type_MAC
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var timeTypes = map[string]int{
+	"unix":     Time_Type_UNIX,
+	"unixms":   Time_Type_UNIXMS,
+	"filetime": Time_Type_FILETIME,
+	"dos":      Time_Type_DOS,
+	"ntp":      Time_Type_NTP,
+	"mac":      Time_Type_MAC,
+}
+
+const (
+	// seconds from 1601-01-01 to 1970-01-01
+	filetimeEpochDelta = 11644473600
+	// seconds from 1900-01-01 to 1970-01-01
+	ntpEpochDelta = 2208988800
+	// seconds from 1904-01-01 to 1970-01-01
+	macEpochDelta = 2082844800
+)
+
+// hasTime reports whether the tag has a time encoding.
+func (c *tagConfig) hasTime() bool {
+	return c != nil && c.time != Time_Type_BLANK
+}
+
+// sizeOfTime returns the size of a time.Time field which has the struct tag cnf.
+func sizeOfTime(cnf *tagConfig) int {
+	if cnf == nil {
+		return 0
+	}
+	switch cnf.time {
+	case Time_Type_UNIX:
+		if cnf.size > 0 {
+			return cnf.size
+		}
+		return 4
+	case Time_Type_DOS, Time_Type_MAC:
+		return 4
+	case Time_Type_UNIXMS, Time_Type_FILETIME, Time_Type_NTP:
+		return 8
+	}
+	return 0
+}
+
+func errTimeTag(v reflect.Value, cnf *tagConfig) error {
+	if !cnf.hasTime() {
+		return fmt.Errorf("Not Supported %s without time tag", v.Type())
+	}
+	if cnf.time == Time_Type_UNIX && cnf.size != 0 && cnf.size != 4 && cnf.size != 8 {
+		return fmt.Errorf("invalid size %d of time=unix", cnf.size)
+	}
+	return nil
+}
+
+// readTime reads an integer timestamp from b and fill time.Time v.
+// Raw value 0 is decoded as zero time.Time, since zero time.Time is encoded as 0.
+func readTime(b []byte, order ByteOrder, v reflect.Value, cnf *tagConfig, index *int) error {
+	if err := errTimeTag(v, cnf); err != nil {
+		return err
+	}
+	size := sizeOfTime(cnf)
+	bs := b[*index : *index+size]
+	*index += size
+
+	var t time.Time
+	switch cnf.time {
+	case Time_Type_UNIX:
+		var sec int64
+		if size == 8 {
+			sec = int64(order.Uint64(bs))
+		} else {
+			sec = int64(order.Uint32(bs))
+		}
+		if sec != 0 {
+			t = time.Unix(sec, 0)
+		}
+	case Time_Type_UNIXMS:
+		if ms := int64(order.Uint64(bs)); ms != 0 {
+			t = time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+		}
+	case Time_Type_FILETIME:
+		if ft := order.Uint64(bs); ft != 0 {
+			t = time.Unix(int64(ft/10000000)-filetimeEpochDelta, int64(ft%10000000)*100)
+		}
+	case Time_Type_DOS:
+		tm := order.Uint16(bs[0:2])
+		dt := order.Uint16(bs[2:4])
+		if tm != 0 || dt != 0 {
+			t = time.Date(int(dt>>9)+1980, time.Month((dt>>5)&0xf), int(dt&0x1f),
+				int(tm>>11), int((tm>>5)&0x3f), int(tm&0x1f)*2, 0, time.UTC)
+		}
+	case Time_Type_NTP:
+		sec := order.Uint32(bs[0:4])
+		frac := order.Uint32(bs[4:8])
+		if sec != 0 || frac != 0 {
+			t = time.Unix(int64(sec)-ntpEpochDelta, int64((uint64(frac)*1000000000)>>32))
+		}
+	case Time_Type_MAC:
+		if sec := order.Uint32(bs); sec != 0 {
+			t = time.Unix(int64(sec)-macEpochDelta, 0)
+		}
+	}
+	if !t.IsZero() {
+		t = t.UTC()
+	}
+
+	if !v.CanSet() {
+		return fmt.Errorf("can not set %v\n", v)
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// inRange returns an error if sec is not in [min, max].
+func inRange(t time.Time, sec, min, max int64, name string) error {
+	if sec < min || sec > max {
+		return fmt.Errorf("time %s is out of range of %s", t, name)
+	}
+	return nil
+}
+
+// writeTime writes time.Time v to b as an integer timestamp.
+// Zero time.Time is encoded as 0.
+func writeTime(v reflect.Value, order ByteOrder, cnf *tagConfig, b []byte, index *int) error {
+	if err := errTimeTag(v, cnf); err != nil {
+		return err
+	}
+	size := sizeOfTime(cnf)
+	bs := b[*index : *index+size]
+
+	t := v.Interface().(time.Time)
+	if t.IsZero() {
+		fill(b, index, size, 0)
+		return nil
+	}
+	sec := t.Unix()
+
+	switch cnf.time {
+	case Time_Type_UNIX:
+		if size == 8 {
+			order.PutUint64(bs, uint64(sec))
+		} else {
+			if err := inRange(t, sec, 0, 1<<32-1, "unix"); err != nil {
+				return err
+			}
+			order.PutUint32(bs, uint32(sec))
+		}
+	case Time_Type_UNIXMS:
+		order.PutUint64(bs, uint64(sec*1000+int64(t.Nanosecond())/int64(time.Millisecond)))
+	case Time_Type_FILETIME:
+		if err := inRange(t, sec, -filetimeEpochDelta, (1<<63-1)/10000000-filetimeEpochDelta, "filetime"); err != nil {
+			return err
+		}
+		order.PutUint64(bs, uint64(sec+filetimeEpochDelta)*10000000+uint64(t.Nanosecond()/100))
+	case Time_Type_DOS:
+		t = t.UTC()
+		if t.Year() < 1980 || t.Year() > 2107 {
+			return fmt.Errorf("time %s is out of range of dos", t)
+		}
+		order.PutUint16(bs[0:2], uint16(t.Hour()<<11|t.Minute()<<5|t.Second()/2))
+		order.PutUint16(bs[2:4], uint16((t.Year()-1980)<<9|int(t.Month())<<5|t.Day()))
+	case Time_Type_NTP:
+		if err := inRange(t, sec, -ntpEpochDelta, 1<<32-1-ntpEpochDelta, "ntp"); err != nil {
+			return err
+		}
+		order.PutUint32(bs[0:4], uint32(sec+ntpEpochDelta))
+		order.PutUint32(bs[4:8], uint32((uint64(t.Nanosecond())<<32)/1000000000))
+	case Time_Type_MAC:
+		if err := inRange(t, sec, -macEpochDelta, 1<<32-1-macEpochDelta, "mac"); err != nil {
+			return err
+		}
+		order.PutUint32(bs, uint32(sec+macEpochDelta))
+	}
+	*index += size
+	return nil
+}
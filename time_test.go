@@ -0,0 +1,137 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/nokute78/go-endian"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	type S struct {
+		Unix     time.Time `endian:"time=unix"`
+		UnixMS   time.Time `endian:"time=unixms,LE"`
+		FileTime time.Time `endian:"time=filetime"`
+		DOS      time.Time `endian:"time=dos"`
+		NTP      time.Time `endian:"time=ntp"`
+		Mac      time.Time `endian:"time=mac"`
+		Unix64   time.Time `endian:"time=unix,size=8"`
+	}
+	raw, err := hex.DecodeString("5f5e1000" + "7b806e8774010000" + "01d689c921b95687" + "6354512d" + "e3088e8080000000" + "db83c080" + "ffffffffffffffff")
+	if err != nil {
+		t.Fatalf("hex.DecodeString err=%s", err)
+	}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+
+	base := time.Date(2020, time.September, 13, 12, 26, 40, 0, time.UTC)
+	expects := []struct {
+		name   string
+		given  time.Time
+		expect time.Time
+	}{
+		{"Unix", s.Unix, base},
+		{"UnixMS", s.UnixMS, base.Add(123 * time.Millisecond)},
+		{"FileTime", s.FileTime, base.Add(123456700 * time.Nanosecond)},
+		{"DOS", s.DOS, base},
+		{"NTP", s.NTP, base.Add(500 * time.Millisecond)},
+		{"Mac", s.Mac, base},
+		{"Unix64", s.Unix64, time.Unix(-1, 0)},
+	}
+	for _, e := range expects {
+		if !e.given.Equal(e.expect) {
+			t.Errorf("%s mismatch given=%s expect=%s", e.name, e.given, e.expect)
+		}
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestTimeZero(t *testing.T) {
+	type S struct {
+		Unix     time.Time `endian:"time=unix"`
+		UnixMS   time.Time `endian:"time=unixms"`
+		FileTime time.Time `endian:"time=filetime"`
+		DOS      time.Time `endian:"time=dos"`
+		NTP      time.Time `endian:"time=ntp"`
+		Mac      time.Time `endian:"time=mac"`
+		Unix64   time.Time `endian:"time=unix,size=8"`
+	}
+	size := 4 + 8 + 8 + 4 + 8 + 4 + 8
+
+	var s S
+	if err := endian.Unmarshal(make([]byte, size), endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	for i, tm := range []time.Time{s.Unix, s.UnixMS, s.FileTime, s.DOS, s.NTP, s.Mac, s.Unix64} {
+		if !tm.IsZero() {
+			t.Errorf("%d: not zero given=%s", i, tm)
+		}
+	}
+
+	ret, err := endian.Marshal(endian.LittleEndian, S{})
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if expect := make([]byte, size); bytes.Compare(ret, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, expect)
+	}
+
+	/* round trip */
+	s = S{Unix: time.Now()}
+	if err := endian.Unmarshal(ret, endian.LittleEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s != (S{}) {
+		t.Errorf("not zero given=%+v", s)
+	}
+}
+
+func TestTimeError(t *testing.T) {
+	type NoTag struct {
+		T time.Time
+	}
+	if _, err := endian.Marshal(endian.LittleEndian, NoTag{T: time.Now()}); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	type DOS struct {
+		T time.Time `endian:"time=dos"`
+	}
+	if _, err := endian.Marshal(endian.LittleEndian, DOS{T: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)}); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	type Unix struct {
+		T time.Time `endian:"time=unix"`
+	}
+	if _, err := endian.Marshal(endian.LittleEndian, Unix{T: time.Date(2200, time.January, 1, 0, 0, 0, 0, time.UTC)}); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"reflect"
+)
+
+// Uint128 represents an unsigned 128 bit integer.
+// It is encoded in the byte order like other integers.
+type Uint128 struct {
+	Hi uint64
+	Lo uint64
+}
+
+const uint128Size = 16
+
+func init() {
+	RegisterCodec(reflect.TypeOf(Uint128{}), uint128Codec{})
+}
+
+// uint128Codec is the Codec of Uint128.
+type uint128Codec struct{}
+
+func (uint128Codec) Size() int {
+	return uint128Size
+}
+
+func (uint128Codec) Decode(b []byte, order ByteOrder) (interface{}, error) {
+	if order == BigEndian {
+		return Uint128{Hi: order.Uint64(b[0:8]), Lo: order.Uint64(b[8:16])}, nil
+	}
+	return Uint128{Lo: order.Uint64(b[0:8]), Hi: order.Uint64(b[8:16])}, nil
+}
+
+func (uint128Codec) Encode(b []byte, order ByteOrder, v interface{}) error {
+	u := v.(Uint128)
+	if order == BigEndian {
+		order.PutUint64(b[0:8], u.Hi)
+		order.PutUint64(b[8:16], u.Lo)
+	} else {
+		order.PutUint64(b[0:8], u.Lo)
+		order.PutUint64(b[8:16], u.Hi)
+	}
+	return nil
+}
+
+// Uint128From64 returns Uint128 of v.
+func Uint128From64(v uint64) Uint128 {
+	return Uint128{Lo: v}
+}
+
+// Uint128FromBig returns Uint128 of b.
+// It returns an error if b is negative or overflows 128 bit.
+func Uint128FromBig(b *big.Int) (Uint128, error) {
+	if b.Sign() < 0 || b.BitLen() > 128 {
+		return Uint128{}, fmt.Errorf("%s overflows Uint128", b)
+	}
+	lo := new(big.Int).And(b, new(big.Int).SetUint64(^uint64(0)))
+	hi := new(big.Int).Rsh(b, 64)
+	return Uint128{Hi: hi.Uint64(), Lo: lo.Uint64()}, nil
+}
+
+// Big returns u as *big.Int.
+func (u Uint128) Big() *big.Int {
+	ret := new(big.Int).SetUint64(u.Hi)
+	ret.Lsh(ret, 64)
+	return ret.Or(ret, new(big.Int).SetUint64(u.Lo))
+}
+
+// String returns u in decimal.
+func (u Uint128) String() string {
+	return u.Big().String()
+}
+
+// IsZero reports whether u is 0.
+func (u Uint128) IsZero() bool {
+	return u.Hi == 0 && u.Lo == 0
+}
+
+// Cmp compares u and v and returns -1, 0 or +1.
+func (u Uint128) Cmp(v Uint128) int {
+	switch {
+	case u.Hi < v.Hi || (u.Hi == v.Hi && u.Lo < v.Lo):
+		return -1
+	case u == v:
+		return 0
+	}
+	return 1
+}
+
+// Add returns u+v. It wraps around on overflow.
+func (u Uint128) Add(v Uint128) Uint128 {
+	lo, carry := bits.Add64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Add64(u.Hi, v.Hi, carry)
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Sub returns u-v. It wraps around on underflow.
+func (u Uint128) Sub(v Uint128) Uint128 {
+	lo, borrow := bits.Sub64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Sub64(u.Hi, v.Hi, borrow)
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Mul returns u*v. It wraps around on overflow.
+func (u Uint128) Mul(v Uint128) Uint128 {
+	hi, lo := bits.Mul64(u.Lo, v.Lo)
+	hi += u.Hi*v.Lo + u.Lo*v.Hi
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// QuoRem returns u/v and u%v. It panics if v is 0.
+func (u Uint128) QuoRem(v Uint128) (Uint128, Uint128) {
+	if v.IsZero() {
+		panic("endian: division by zero")
+	}
+	q, r := new(big.Int).QuoRem(u.Big(), v.Big(), new(big.Int))
+	qu, _ := Uint128FromBig(q)
+	ru, _ := Uint128FromBig(r)
+	return qu, ru
+}
+
+// And returns u&v.
+func (u Uint128) And(v Uint128) Uint128 {
+	return Uint128{Hi: u.Hi & v.Hi, Lo: u.Lo & v.Lo}
+}
+
+// Or returns u|v.
+func (u Uint128) Or(v Uint128) Uint128 {
+	return Uint128{Hi: u.Hi | v.Hi, Lo: u.Lo | v.Lo}
+}
+
+// Xor returns u^v.
+func (u Uint128) Xor(v Uint128) Uint128 {
+	return Uint128{Hi: u.Hi ^ v.Hi, Lo: u.Lo ^ v.Lo}
+}
+
+// Lsh returns u<<n.
+func (u Uint128) Lsh(n uint) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{Hi: u.Lo << (n - 64)}
+	case n == 0:
+		return u
+	}
+	return Uint128{Hi: u.Hi<<n | u.Lo>>(64-n), Lo: u.Lo << n}
+}
+
+// Rsh returns u>>n.
+func (u Uint128) Rsh(n uint) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{Lo: u.Hi >> (n - 64)}
+	case n == 0:
+		return u
+	}
+	return Uint128{Hi: u.Hi >> n, Lo: u.Lo>>n | u.Hi<<(64-n)}
+}
@@ -0,0 +1,193 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestUint128(t *testing.T) {
+	type S struct {
+		A endian.Uint128
+		B endian.Uint128 `endian:"LE"`
+	}
+	raw := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+		0x0f, 0x0e, 0x0d, 0x0c, 0x0b, 0x0a, 0x09, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, 0x00,
+	}
+	expect := endian.Uint128{Hi: 0x0001020304050607, Lo: 0x08090a0b0c0d0e0f}
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.A != expect || s.B != expect {
+		t.Errorf("mismatch given=%+v expect=%+v", s, expect)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+}
+
+func TestUint128Arithmetic(t *testing.T) {
+	max := endian.Uint128{Hi: ^uint64(0), Lo: ^uint64(0)}
+	one := endian.Uint128From64(1)
+
+	if ret := max.Add(one); !ret.IsZero() {
+		t.Errorf("max+1 given=%s expect=0", ret)
+	}
+	if ret := (endian.Uint128{}).Sub(one); ret != max {
+		t.Errorf("0-1 given=%s expect=%s", ret, max)
+	}
+	if ret := endian.Uint128From64(^uint64(0)).Add(one); ret != (endian.Uint128{Hi: 1}) {
+		t.Errorf("carry given=%+v", ret)
+	}
+
+	a := endian.Uint128{Hi: 0x1234, Lo: 0xfedcba9876543210}
+	b := endian.Uint128From64(0xabcdef)
+	expect := new(big.Int).Mul(a.Big(), b.Big())
+	if ret := a.Mul(b); ret.Big().Cmp(expect) != 0 {
+		t.Errorf("mul given=%s expect=%s", ret, expect)
+	}
+	q, r := a.QuoRem(b)
+	if q.Mul(b).Add(r) != a {
+		t.Errorf("quorem given q=%s r=%s", q, r)
+	}
+
+	if ret := one.Lsh(127).Rsh(127); ret != one {
+		t.Errorf("shift given=%s", ret)
+	}
+	if ret := one.Lsh(64); ret != (endian.Uint128{Hi: 1}) {
+		t.Errorf("shift given=%+v", ret)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(a) != 0 {
+		t.Errorf("Cmp mismatch")
+	}
+	if s := max.String(); s != "340282366920938463463374607431768211455" {
+		t.Errorf("String given=%s", s)
+	}
+
+	u, err := endian.Uint128FromBig(max.Big())
+	if err != nil || u != max {
+		t.Errorf("Uint128FromBig given=%s err=%v", u, err)
+	}
+	if _, err := endian.Uint128FromBig(new(big.Int).Lsh(big.NewInt(1), 128)); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	type S struct {
+		U   *big.Int `endian:"size=32"`
+		I   *big.Int `endian:"size=4,signed,LE"`
+		Val big.Int  `endian:"size=3"`
+	}
+	raw := make([]byte, 39)
+	raw[0] = 0x80
+	raw[31] = 0x01
+	copy(raw[32:], []byte{0xfe, 0xff, 0xff, 0xff, 0x01, 0x00, 0x00})
+
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	u := new(big.Int).Lsh(big.NewInt(1), 255)
+	u.Add(u, big.NewInt(1))
+	if s.U.Cmp(u) != 0 {
+		t.Errorf("U mismatch given=%s expect=%s", s.U, u)
+	}
+	if s.I.Int64() != -2 {
+		t.Errorf("I mismatch given=%s expect=-2", s.I)
+	}
+	if s.Val.Int64() != 0x10000 {
+		t.Errorf("Val mismatch given=%s expect=%d", &s.Val, 0x10000)
+	}
+
+	ret, err := endian.Marshal(endian.BigEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	s.I = big.NewInt(1 << 31)
+	if _, err := endian.Marshal(endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+	s.I = nil
+	s.U = big.NewInt(-1)
+	if _, err := endian.Marshal(endian.BigEndian, &s); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+// bigCodec encodes *big.Int as uint16.
+type bigCodec struct{}
+
+func (bigCodec) Size() int {
+	return 2
+}
+
+func (bigCodec) Decode(b []byte, order endian.ByteOrder) (interface{}, error) {
+	return big.NewInt(int64(order.Uint16(b))), nil
+}
+
+func (bigCodec) Encode(b []byte, order endian.ByteOrder, v interface{}) error {
+	order.PutUint16(b, uint16(v.(*big.Int).Uint64()))
+	return nil
+}
+
+func TestBigIntCodec(t *testing.T) {
+	type S struct {
+		N *big.Int
+		V uint8
+	}
+	typ := reflect.TypeOf(&big.Int{})
+	endian.RegisterCodec(typ, bigCodec{})
+	defer endian.RegisterCodec(typ, nil)
+
+	raw := []byte{0x01, 0x02, 0x03}
+	var s S
+	if err := endian.Unmarshal(raw, endian.BigEndian, &s); err != nil {
+		t.Fatalf("endian.Unmarshal err=%s", err)
+	}
+	if s.N.Int64() != 0x0102 || s.V != 3 {
+		t.Errorf("mismatch given=%+v", s)
+	}
+	ret, err := endian.Marshal(endian.BigEndian, &s)
+	if err != nil {
+		t.Fatalf("endian.Marshal err=%s", err)
+	}
+	if bytes.Compare(ret, raw) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", ret, raw)
+	}
+
+	var n *big.Int
+	if err := endian.Unmarshal(raw, endian.LittleEndian, &n); err != nil || n.Int64() != 0x0201 {
+		t.Errorf("mismatch given=%v err=%v", n, err)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// View is a typed view over T encoded in a byte slice.
+// Fields are read and written directly in the byte slice at their offsets without decoding the whole T.
+// The layout is computed from the zero value of T, so slices of T are treated as empty.
+// The offset of a field is computed from the type on the first access and cached per path.
+type View[T any] struct {
+	b      []byte
+	order  ByteOrder
+	root   *Layout
+	opts   *Options
+	fields sync.Map // path to *Layout
+}
+
+// NewView returns a View of T over b. b is not copied.
+func NewView[T any](b []byte, order ByteOrder) (*View[T], error) {
+	return NewViewWithOptions[T](b, order, nil)
+}
+
+// NewViewWithOptions is like NewView but the behavior is customized by opts.
+// If opts is nil, it is same as NewView.
+func NewViewWithOptions[T any](b []byte, order ByteOrder, opts *Options) (*View[T], error) {
+	opts = optionsOrDefault(opts)
+	var zero T
+	l, err := rootLayout(&zero, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < l.Size {
+		return nil, fmt.Errorf("endian.NewView:short buffer, expect=%d byte, given=%d byte", l.Size, len(b))
+	}
+	return &View[T]{b: b, order: order, root: l, opts: opts}, nil
+}
+
+// Bytes returns the underlying byte slice.
+func (v *View[T]) Bytes() []byte {
+	return v.b
+}
+
+// Layout returns the layout of T. It is computed on each call since it has all elements.
+// If T has an invalid struct tag, the layout has no fields.
+func (v *View[T]) Layout() *Layout {
+	l, err := LayoutOfWithOptions(v.root.value.Addr().Interface(), v.opts)
+	if err != nil {
+		return v.root
+	}
+	return l
+}
+
+// Field returns the bytes of the field pointed by path. It shares the underlying byte slice.
+func (v *View[T]) Field(path string) ([]byte, error) {
+	l, err := v.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.b[l.Offset:v.end(l)], nil
+}
+
+// Get decodes the field pointed by path into dst. dst must be a pointer to the type of the field.
+func (v *View[T]) Get(path string, dst interface{}) error {
+	l, err := v.lookup(path)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Type() != l.Type {
+		return fmt.Errorf("endian: %T is not a pointer to %s", dst, l.Type)
+	}
+	return decodeLayout(v.b[l.Offset:v.end(l)], v.order, l, rv.Elem())
+}
+
+// Set encodes value as the field pointed by path in place.
+func (v *View[T]) Set(path string, value interface{}) error {
+	l, err := v.lookup(path)
+	if err != nil {
+		return err
+	}
+	rv, size, err := layoutValue(l, value)
+	if err != nil {
+		return err
+	}
+	if l.Offset+size > len(v.b) {
+		return fmt.Errorf("endian: %s exceeds the buffer. size=%d byte", path, size)
+	}
+	return encodeLayout(v.b[l.Offset:l.Offset+size], v.order, l, rv)
+}
+
+// Load decodes the whole T.
+func (v *View[T]) Load() (T, error) {
+	var ret T
+	err := UnmarshalWithOptions(v.b, v.order, &ret, v.opts)
+	return ret, err
+}
+
+// Store encodes t to the underlying byte slice in place.
+func (v *View[T]) Store(t T) error {
+	rv := reflect.ValueOf(&t).Elem()
+	if c := sizeOfValue(rv, true, v.opts); c > len(v.b) {
+		return fmt.Errorf("endian: %s exceeds the buffer. size=%d byte", rv.Type(), c)
+	}
+	index := 0
+	err := write(rv, v.order, v.b, &index, v.opts)
+	if err != nil && err != errCannotInterface {
+		return err
+	}
+	return nil
+}
+
+// lookup returns the layout of the field pointed by path. It is cached per path.
+func (v *View[T]) lookup(path string) (*Layout, error) {
+	if l, ok := v.fields.Load(path); ok {
+		return l.(*Layout), nil
+	}
+	l, err := v.root.lookup(path, v.opts)
+	if err != nil {
+		return nil, err
+	}
+	v.fields.Store(path, l)
+	return l, nil
+}
+
+// end returns the end offset of the field l.
+// rest field and length prefixed string without size tag end at the end of the buffer.
+func (v *View[T]) end(l *Layout) int {
+	if l.unbounded() {
+		return len(v.b)
+	}
+	return l.Offset + l.Size
+}
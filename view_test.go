@@ -0,0 +1,138 @@
+/*
+   Copyright 2020 Takahiro Yamashita
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package endian_test
+
+import (
+	"bytes"
+	"github.com/nokute78/go-endian"
+	"testing"
+)
+
+type viewPacket struct {
+	Src   [4]byte `endian:"ipv4"`
+	Len   uint16  `endian:"BE"`
+	Flags [2]uint8
+	Seq   uint32
+	Data  []byte `endian:"rest"`
+}
+
+func TestView(t *testing.T) {
+	buf := []byte{
+		10, 0, 0, 1,
+		0x00, 0x10,
+		0x01, 0x02,
+		0x04, 0x03, 0x02, 0x01,
+		0xaa, 0xbb,
+	}
+	v, err := endian.NewView[viewPacket](buf, endian.LittleEndian)
+	if err != nil {
+		t.Fatalf("endian.NewView err=%s", err)
+	}
+
+	var l uint16
+	if err := v.Get("Len", &l); err != nil || l != 0x10 {
+		t.Errorf("Len mismatch given=%#x err=%v", l, err)
+	}
+	var seq uint32
+	if err := v.Get("Seq", &seq); err != nil || seq != 0x01020304 {
+		t.Errorf("Seq mismatch given=%#x err=%v", seq, err)
+	}
+	var flags [2]uint8
+	if err := v.Get("Flags", &flags); err != nil || flags != [2]uint8{1, 2} {
+		t.Errorf("Flags mismatch given=%v err=%v", flags, err)
+	}
+	if b, err := v.Field("Data"); err != nil || bytes.Compare(b, []byte{0xaa, 0xbb}) != 0 {
+		t.Errorf("Data mismatch given=%x err=%v", b, err)
+	}
+	if err := v.Get("Seq", &l); err == nil {
+		t.Errorf("error is not returned")
+	}
+
+	if err := v.Set("Len", uint16(0x1234)); err != nil {
+		t.Fatalf("Set err=%s", err)
+	}
+	if err := v.Set("Seq", uint32(0xdeadbeef)); err != nil {
+		t.Fatalf("Set err=%s", err)
+	}
+	expect := []byte{
+		10, 0, 0, 1,
+		0x12, 0x34,
+		0x01, 0x02,
+		0xef, 0xbe, 0xad, 0xde,
+		0xaa, 0xbb,
+	}
+	if bytes.Compare(buf, expect) != 0 {
+		t.Errorf("mismatch\n given=%x\n expect=%x", buf, expect)
+	}
+
+	p, err := v.Load()
+	if err != nil {
+		t.Fatalf("Load err=%s", err)
+	}
+	if p.Src != [4]byte{10, 0, 0, 1} || p.Seq != 0xdeadbeef || bytes.Compare(p.Data, []byte{0xaa, 0xbb}) != 0 {
+		t.Errorf("Load mismatch given=%+v", p)
+	}
+	p.Seq = 1
+	p.Data = []byte{0xcc, 0xdd}
+	if err := v.Store(p); err != nil {
+		t.Fatalf("Store err=%s", err)
+	}
+	if buf[8] != 1 || buf[13] != 0xdd {
+		t.Errorf("Store mismatch given=%x", buf)
+	}
+
+	if _, err := endian.NewView[viewPacket](buf[:11], endian.LittleEndian); err == nil {
+		t.Errorf("error is not returned")
+	}
+}
+
+func TestViewLarge(t *testing.T) {
+	type Entry struct {
+		Flags uint16
+		Count uint32
+	}
+	type Image struct {
+		Magic   uint32
+		Entries [1 << 12]Entry
+	}
+	buf := make([]byte, 4+6<<12)
+	v, err := endian.NewView[Image](buf, endian.LittleEndian)
+	if err != nil {
+		t.Fatalf("endian.NewView err=%s", err)
+	}
+	if err := v.Set("Entries[100].Count", uint32(0x01020304)); err != nil {
+		t.Fatalf("Set err=%s", err)
+	}
+	var count uint32
+	if err := v.Get("Entries[100].Count", &count); err != nil {
+		t.Fatalf("Get err=%s", err)
+	}
+	if count != 0x01020304 || buf[4+6*100+2] != 0x04 {
+		t.Errorf("mismatch given=%x", count)
+	}
+
+	/* lookups are cached per path */
+	allocs := testing.AllocsPerRun(10, func() {
+		v.Get("Entries[100].Count", &count)
+	})
+	if allocs > 10 {
+		t.Errorf("too many allocations given=%v", allocs)
+	}
+	if l := v.Layout(); len(l.Fields) != 2 || len(l.Fields[1].Fields) != 1<<12 {
+		t.Errorf("layout mismatch")
+	}
+}